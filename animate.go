@@ -0,0 +1,108 @@
+package ggg
+
+import (
+	"fmt"
+	"image"
+	"image/gif"
+
+	"github.com/mknyszek/ggg/internal/quant"
+)
+
+// animationDelay is the time each frame of an animation is shown for, in
+// hundredths of a second.
+const animationDelay = 50
+
+// RenderAnimation renders the plot as an animated GIF of the provided size,
+// with one frame for each distinct value of frameCol, in the order the
+// values first appear in the layers' datasets. Each frame draws only the
+// rows with that value, and is labeled with it. Layers whose datasets don't
+// have frameCol are drawn in every frame. The axis limits and the legend
+// are computed from all the data, so that they're the same in every frame.
+func (p *Plot) RenderAnimation(theme string, width, height int, frameCol AnyColumn) (*gif.GIF, error) {
+	th, err := lookupTheme(theme)
+	if err != nil {
+		return nil, err
+	}
+	frames, err := p.animationFrames(frameCol)
+	if err != nil {
+		return nil, err
+	}
+	anim := &gif.GIF{Config: image.Config{Width: width, Height: height}}
+	for _, fp := range frames {
+		rc := newRasterCanvas(width, height)
+		if err := fp.draw(rc, th, float64(width), float64(height)); err != nil {
+			return nil, err
+		}
+		img := rc.image()
+		anim.Image = append(anim.Image, quant.Paletted(img, quant.Palette(img, 256)))
+		anim.Delay = append(anim.Delay, animationDelay)
+	}
+	return anim, nil
+}
+
+// animationFrames returns a plot for each frame of an animation by
+// frameCol, drawing the rows with one of its distinct values.
+func (p *Plot) animationFrames(frameCol AnyColumn) ([]*Plot, error) {
+	if len(p.layers) == 0 {
+		return nil, fmt.Errorf("no layers to animate")
+	}
+
+	// Collect the distinct values, by key, so that equal instants in
+	// different locations and NaNs each make up a single frame.
+	var values []any
+	seen := make(map[any]bool)
+	for _, l := range p.layers {
+		d := l.dataset()
+		if d == nil {
+			continue
+		}
+		ci, ok := d.colMap[frameCol.colKey()]
+		if !ok {
+			continue
+		}
+		for row := range d.Rows() {
+			v := d.columns[ci].get(row)
+			if k := valueKey(v); !seen[k] {
+				seen[k] = true
+				values = append(values, v)
+			}
+		}
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("column %s not in any layer's dataset", frameCol.Name())
+	}
+
+	// Fix the axis limits, and leave room for the widest frame label, so
+	// that the chart area doesn't move between frames.
+	full := p.prepared()
+	full.computeRanges()
+	opts := full.opts
+	axes := []*axis{&opts.x, &opts.y}
+	if p.usesY2() {
+		axes = append(axes, &opts.y2)
+	}
+	for _, a := range axes {
+		a.userMin, a.userMax, a.userLimits = a.min, a.max, true
+	}
+	labels := make([]string, len(values))
+	for i, v := range values {
+		labels[i] = fmt.Sprintf("%s: %v", frameCol.Name(), v)
+	}
+	opts.frames = labels
+
+	var frames []*Plot
+	for i, v := range values {
+		fp := &Plot{opts: opts}
+		fp.opts.frame = labels[i]
+		for _, l := range p.layers {
+			if d := l.dataset(); d != nil {
+				if _, ok := d.colMap[frameCol.colKey()]; ok {
+					l.AnyLayer = l.where(anyEqualTo(frameCol, v))
+				}
+			}
+			fp.layers = append(fp.layers, l)
+		}
+		frames = append(frames, fp)
+	}
+	return frames, nil
+}
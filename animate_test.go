@@ -0,0 +1,136 @@
+package ggg
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestAnimationFrames(t *testing.T) {
+	th := testTheme(t)
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tokyo := time.FixedZone("JST", 9*60*60)
+
+	type test struct {
+		name string
+
+		// frame sets the frame column of each row, returning it.
+		frame func(d *Dataset, rows int) AnyColumn
+
+		// want is the number of rows in each frame.
+		want []int
+	}
+	for _, ts := range []test{
+		{
+			name: "Strings",
+			frame: func(d *Dataset, rows int) AnyColumn {
+				c := NewColumn[string]("frame")
+				d.AddColumn(c)
+				for row := range rows {
+					c.Set(d, row, []string{"a", "b", "c"}[row%3])
+				}
+				return c
+			},
+			want: []int{4, 3, 3},
+		},
+		{
+			// NaNs make up a single frame, which draws all of them.
+			name: "NaN",
+			frame: func(d *Dataset, rows int) AnyColumn {
+				c := NewColumn[float64]("frame")
+				d.AddColumn(c)
+				for row := range rows {
+					c.Set(d, row, []float64{1, math.NaN()}[row%2])
+				}
+				return c
+			},
+			want: []int{5, 5},
+		},
+		{
+			// Equal instants in different locations are the same frame.
+			name: "Times",
+			frame: func(d *Dataset, rows int) AnyColumn {
+				c := NewColumn[time.Time]("frame")
+				d.AddColumn(c)
+				for row := range rows {
+					v := base.Add(time.Duration(row/5) * time.Hour)
+					if row%2 == 1 {
+						v = v.In(tokyo)
+					}
+					c.Set(d, row, v)
+				}
+				return c
+			},
+			want: []int{5, 5},
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			p := pointsPlot(0, 9).Presentation(Title("Fish"))
+			d := p.layers[0].dataset()
+			frameCol := ts.frame(d, d.Rows())
+			frames, err := p.animationFrames(frameCol)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []int
+			for _, fp := range frames {
+				l := fp.layers[0].AnyLayer.(*Layer[float64, float64])
+				got = append(got, len(slices.Collect(filteredRows(l.Data, l.filter))))
+			}
+			if !slices.Equal(got, ts.want) {
+				t.Errorf("got frames of %v rows, want %v", got, ts.want)
+			}
+
+			// Every frame has the limits of all the data, and the same
+			// chart area, however wide its label.
+			var areas []chartArea
+			for _, fp := range frames {
+				x, y := fp.opts.x, fp.opts.y
+				if !x.userLimits || x.userMin != 0 || x.userMax != 9 || !y.userLimits || y.userMin != 0 || y.userMax != 81 {
+					t.Errorf("frame %q has limits X [%v, %v] and Y [%v, %v], want X [0, 9] and Y [0, 81]",
+						fp.opts.frame, x.userMin, x.userMax, y.userMin, y.userMax)
+				}
+				q := fp.prepared()
+				q.computeRanges()
+				areas = append(areas, q.layout(newRasterCanvas(300, 200), th, 300, 200, false).area)
+			}
+			for i := 1; i < len(areas); i++ {
+				if areas[i] != areas[0] {
+					t.Errorf("frame %d has chart area %+v, but frame 0 has %+v", i, areas[i], areas[0])
+				}
+			}
+		})
+	}
+}
+
+func TestAnimationFrameLabels(t *testing.T) {
+	p := pointsPlot(0, 9).Presentation(Title("A title long enough that it wraps next to a wide frame label"))
+	d := p.layers[0].dataset()
+	c := NewColumn[string]("frame")
+	d.AddColumn(c)
+	for row := range d.Rows() {
+		c.Set(d, row, []string{"a", "a much wider frame label"}[row%2])
+	}
+	frames, err := p.animationFrames(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The title is wrapped the same way in each frame, next to room for
+	// the widest label.
+	th := testTheme(t)
+	rc := newRasterCanvas(400, 300)
+	var widths []float64
+	for _, fp := range frames {
+		q := fp.prepared()
+		q.computeRanges()
+		widths = append(widths, q.layout(rc, th, 400, 300, false).frameWidth)
+	}
+	l := frames[0].layout(rc, th, 400, 300, false)
+	want, _ := measureText(rc, l.axisFont, "frame: a much wider frame label")
+	for i, w := range widths {
+		if w != want {
+			t.Errorf("frame %d leaves %v for its label, want %v", i, w, want)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package ggg
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// annotation is a layer that marks the plot at fixed positions, rather
+// than drawing a dataset.
+type annotation struct {
+	kind           annotationKind
+	x0, x1, y0, y1 float64
+	xk, yk         valueKind
+	text           string
+	opts           annotationOpts
+}
+
+type annotationKind int
+
+const (
+	annotateHLine annotationKind = iota
+	annotateVLine
+	annotateRect
+	annotateLabel
+)
+
+type annotationOpts struct {
+	color color.Color
+	width float64
+	text  string
+}
+
+// AnnotationOption customizes an annotation.
+type AnnotationOption func(*annotationOpts)
+
+// AnnotationColor sets the color of an annotation. By default, annotations
+// are drawn in the theme's foreground color, and rectangles are filled with
+// a translucent version of it.
+func AnnotationColor(c color.Color) AnnotationOption {
+	return func(opts *annotationOpts) {
+		opts.color = c
+	}
+}
+
+// AnnotationWidth sets the width of an annotation's line.
+func AnnotationWidth(w float64) AnnotationOption {
+	return func(opts *annotationOpts) {
+		opts.width = w
+	}
+}
+
+// AnnotationText labels a line or rectangle annotation with text.
+func AnnotationText(text string) AnnotationOption {
+	return func(opts *annotationOpts) {
+		opts.text = text
+	}
+}
+
+func newAnnotation(a *annotation, opts []AnnotationOption) AnyLayer {
+	a.opts.width = 1.5
+	for _, opt := range opts {
+		opt(&a.opts)
+	}
+	return a
+}
+
+// HLine returns a layer that draws a horizontal line across the plot at y,
+// for example to mark a threshold.
+func HLine[Y Value](y Y, opts ...AnnotationOption) AnyLayer {
+	return newAnnotation(&annotation{
+		kind: annotateHLine,
+		y0:   toFloat(y),
+		y1:   toFloat(y),
+		xk:   valueNone,
+		yk:   kindOf[Y](),
+	}, opts)
+}
+
+// VLine returns a layer that draws a vertical line across the plot at x,
+// for example to mark an event.
+func VLine[X Value](x X, opts ...AnnotationOption) AnyLayer {
+	return newAnnotation(&annotation{
+		kind: annotateVLine,
+		x0:   toFloat(x),
+		x1:   toFloat(x),
+		xk:   kindOf[X](),
+		yk:   valueNone,
+	}, opts)
+}
+
+// Rect returns a layer that shades the rectangle spanning x0 to x1 and y0
+// to y1, for example to highlight a period of time.
+func Rect[X, Y Value](x0, x1 X, y0, y1 Y, opts ...AnnotationOption) AnyLayer {
+	return newAnnotation(&annotation{
+		kind: annotateRect,
+		x0:   toFloat(x0),
+		x1:   toFloat(x1),
+		y0:   toFloat(y0),
+		y1:   toFloat(y1),
+		xk:   kindOf[X](),
+		yk:   kindOf[Y](),
+	}, opts)
+}
+
+// Label returns a layer that draws text centered on x and y.
+func Label[X, Y Value](x X, y Y, text string, opts ...AnnotationOption) AnyLayer {
+	return newAnnotation(&annotation{
+		kind: annotateLabel,
+		x0:   toFloat(x),
+		x1:   toFloat(x),
+		y0:   toFloat(y),
+		y1:   toFloat(y),
+		xk:   kindOf[X](),
+		yk:   kindOf[Y](),
+		text: text,
+	}, opts)
+}
+
+// xRange is empty if the annotation spans the whole X axis.
+func (a *annotation) xRange() (lo, hi float64) {
+	if a.xk == valueNone {
+		return math.Inf(1), math.Inf(-1)
+	}
+	return min(a.x0, a.x1), max(a.x0, a.x1)
+}
+
+// yRange is empty if the annotation spans the whole Y axis.
+func (a *annotation) yRange() (lo, hi float64) {
+	if a.yk == valueNone {
+		return math.Inf(1), math.Inf(-1)
+	}
+	return min(a.y0, a.y1), max(a.y0, a.y1)
+}
+
+func (a *annotation) xKind() valueKind {
+	return a.xk
+}
+
+func (a *annotation) yKind() valueKind {
+	return a.yk
+}
+
+func (a *annotation) legend(*Theme) []legendEntry {
+	return nil
+}
+
+func (a *annotation) dataset() *Dataset {
+	return nil
+}
+
+func (a *annotation) where(Filter) AnyLayer {
+	return a
+}
+
+func (a *annotation) render(c canvas, theme *Theme, xScale, yScale scaleFunc, scaleFactor float64) error {
+	return fmt.Errorf("annotation can only be drawn within a chart area")
+}
+
+func (a *annotation) renderArea(c canvas, theme *Theme, xScale, yScale scaleFunc, scaleFactor float64, area chartArea) error {
+	col := a.opts.color
+	if col == nil {
+		col = theme.ForegroundColor
+	}
+	tf := typeface{theme.AnnotationFont, 14 * scaleFactor}
+	pad := fontHeight(c, tf) / 4
+
+	beginGroup(c, "annotation", "")
+	defer endGroup(c)
+	c.setColor(col)
+	c.setLineWidth(a.opts.width * scaleFactor)
+	switch a.kind {
+	case annotateHLine:
+		y := yScale(a.y0)
+		c.moveTo(area.x0, y)
+		c.lineTo(area.x1, y)
+		c.stroke()
+		drawText(c, tf, a.opts.text, area.x1-pad, y-pad, 1, 0)
+	case annotateVLine:
+		x := xScale(a.x0)
+		c.moveTo(x, area.y0)
+		c.lineTo(x, area.y1)
+		c.stroke()
+		drawText(c, tf, a.opts.text, x+pad, area.y0+pad, 0, 1)
+	case annotateRect:
+		x0, x1 := xScale(a.x0), xScale(a.x1)
+		y0, y1 := yScale(a.y0), yScale(a.y1)
+		left, top := min(x0, x1), min(y0, y1)
+		if a.opts.color == nil {
+			faint := color.NRGBAModel.Convert(col).(color.NRGBA)
+			faint.A /= 5
+			c.setColor(faint)
+		}
+		c.rect(left, top, math.Abs(x1-x0), math.Abs(y1-y0))
+		c.fill()
+		c.setColor(col)
+		drawText(c, tf, a.opts.text, left+pad, top+pad, 0, 1)
+	case annotateLabel:
+		drawText(c, tf, a.text, xScale(a.x0), yScale(a.y0), 0.5, 0.35)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package ggg
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnnotationRanges(t *testing.T) {
+	empty := [2]float64{math.Inf(1), math.Inf(-1)}
+	t0 := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	type test struct {
+		name         string
+		layer        AnyLayer
+		x, y         [2]float64
+		xKind, yKind valueKind
+	}
+	for _, ts := range []test{
+		{
+			// Lines span the whole of the other axis.
+			name:  "HLine",
+			layer: HLine(5.0),
+			x:     empty, y: [2]float64{5, 5},
+			xKind: valueNone, yKind: valueNumber,
+		},
+		{
+			name:  "VLine",
+			layer: VLine(t0),
+			x:     [2]float64{toFloat(t0), toFloat(t0)}, y: empty,
+			xKind: valueTime, yKind: valueNone,
+		},
+		{
+			name:  "Rect",
+			layer: Rect(3.0, 1.0, 10.0, -2.0),
+			x:     [2]float64{1, 3}, y: [2]float64{-2, 10},
+			xKind: valueNumber, yKind: valueNumber,
+		},
+		{
+			name:  "Label",
+			layer: Label(2.0, time.Second, "here"),
+			x:     [2]float64{2, 2}, y: [2]float64{toFloat(time.Second), toFloat(time.Second)},
+			xKind: valueNumber, yKind: valueDuration,
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			if lo, hi := ts.layer.xRange(); lo != ts.x[0] || hi != ts.x[1] {
+				t.Errorf("got X range [%v, %v], want %v", lo, hi, ts.x)
+			}
+			if lo, hi := ts.layer.yRange(); lo != ts.y[0] || hi != ts.y[1] {
+				t.Errorf("got Y range [%v, %v], want %v", lo, hi, ts.y)
+			}
+			if k := ts.layer.xKind(); k != ts.xKind {
+				t.Errorf("got X kind %v, want %v", k, ts.xKind)
+			}
+			if k := ts.layer.yKind(); k != ts.yKind {
+				t.Errorf("got Y kind %v, want %v", k, ts.yKind)
+			}
+		})
+	}
+}
+
+func TestAnnotatedPlot(t *testing.T) {
+	type test struct {
+		name  string
+		layer AnyLayer
+		x, y  [2]float64
+	}
+	for _, ts := range []test{
+		{
+			// A line only extends the axis it's placed along.
+			name:  "HLine",
+			layer: HLine(200.0, AnnotationText("limit")),
+			x:     [2]float64{0, 10}, y: [2]float64{0, 200},
+		},
+		{
+			name:  "VLine",
+			layer: VLine(-5.0, AnnotationText("limit")),
+			x:     [2]float64{-5, 10}, y: [2]float64{0, 100},
+		},
+		{
+			name:  "Rect",
+			layer: Rect(2.0, 20.0, -10.0, 50.0, AnnotationText("limit")),
+			x:     [2]float64{0, 20}, y: [2]float64{-10, 100},
+		},
+		{
+			name:  "Label",
+			layer: Label(5.0, 150.0, "limit"),
+			x:     [2]float64{0, 10}, y: [2]float64{0, 150},
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			p := pointsPlot(0, 10).Layer(ts.layer)
+			q := p.prepared()
+			q.computeRanges()
+			if x := q.opts.x; x.min != ts.x[0] || x.max != ts.x[1] {
+				t.Errorf("got X range [%v, %v], want %v", x.min, x.max, ts.x)
+			}
+			if y := q.opts.y; y.min != ts.y[0] || y.max != ts.y[1] {
+				t.Errorf("got Y range [%v, %v], want %v", y.min, y.max, ts.y)
+			}
+
+			var buf bytes.Buffer
+			sc := newSVGCanvas(&buf, 300, 200)
+			if err := p.draw(sc, testTheme(t), 300, 200); err != nil {
+				t.Fatal(err)
+			}
+			if err := sc.finish(); err != nil {
+				t.Fatal(err)
+			}
+			if out := buf.String(); !strings.Contains(out, `<g class="annotation">`) || !strings.Contains(out, ">limit<") {
+				t.Errorf("annotation and its text weren't drawn:\n%s", out)
+			}
+		})
+	}
+}
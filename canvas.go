@@ -0,0 +1,250 @@
+package ggg
+
+import (
+	"image/color"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+)
+
+// canvas is a surface that plots are drawn onto. Coordinates are in pixels,
+// with the origin at the top-left corner.
+//
+// Like gg.Context, shapes are accumulated into a current path which is then
+// either stroked or filled, which also clears the path.
+type canvas interface {
+	setColor(c color.Color)
+	setLineWidth(w float64)
+	setLineCap(lc lineCap)
+	setLineJoin(lj lineJoin)
+
+	// setFont sets the font used by drawString.
+	setFont(f *truetype.Font, size float64)
+
+	// face returns a face for f at size, used to measure text.
+	face(f *truetype.Font, size float64) font.Face
+
+	moveTo(x, y float64)
+	lineTo(x, y float64)
+	closePath()
+	circle(x, y, r float64)
+	rect(x, y, w, h float64)
+	stroke()
+	fill()
+
+	// drawString draws s with its baseline starting at (x, y).
+	drawString(s string, x, y float64)
+
+	// push saves the graphics state, and pop restores it.
+	push()
+	pop()
+
+	translate(x, y float64)
+	rotate(angle float64)
+}
+
+// groupCanvas is implemented by canvases that preserve the structure of a
+// plot, for example to make it interactive.
+type groupCanvas interface {
+	// beginGroup starts a group of drawing operations making up one part of
+	// the plot. series identifies the data drawn in the group, if any.
+	beginGroup(class, series string)
+	endGroup()
+}
+
+func beginGroup(c canvas, class, series string) {
+	if gc, ok := c.(groupCanvas); ok {
+		gc.beginGroup(class, series)
+	}
+}
+
+func endGroup(c canvas) {
+	if gc, ok := c.(groupCanvas); ok {
+		gc.endGroup()
+	}
+}
+
+// pointRecorder is implemented by canvases that track the position of
+// each data point, along with the underlying data.
+type pointRecorder interface {
+	recordPoint(series string, x, y float64, fields []field)
+}
+
+// field is a named value describing a data point.
+type field struct {
+	name, value string
+}
+
+type lineCap int
+
+const (
+	lineCapRound lineCap = iota
+	lineCapButt
+	lineCapSquare
+)
+
+type lineJoin int
+
+const (
+	lineJoinRound lineJoin = iota
+	lineJoinBevel
+)
+
+// faceCache creates and caches font faces for canvases. Faces aren't safe
+// for concurrent use, so each canvas has its own.
+type faceCache map[faceKey]font.Face
+
+type faceKey struct {
+	f    *truetype.Font
+	size float64
+}
+
+func (fc faceCache) face(f *truetype.Font, size float64) font.Face {
+	k := faceKey{f, size}
+	if face, ok := fc[k]; ok {
+		return face
+	}
+	face := truetype.NewFace(f, &truetype.Options{Size: size, SubPixelsX: 32, SubPixelsY: 8})
+	fc[k] = face
+	return face
+}
+
+// typeface is a font at a particular size.
+type typeface struct {
+	font *truetype.Font
+	size float64
+}
+
+// superscriptScale is the size of superscript text relative to the text
+// around it.
+const superscriptScale = 0.7
+
+// measureText returns the width and height of s set in tf.
+func measureText(cv canvas, tf typeface, s string) (w, h float64) {
+	for _, r := range superscriptRuns(s) {
+		size := tf.size
+		if r.superscript {
+			size *= superscriptScale
+		}
+		w += float64(font.MeasureString(cv.face(tf.font, size), r.text)) / 64
+	}
+	return w, fontHeight(cv, tf)
+}
+
+// fontHeight returns the recommended line height for tf.
+func fontHeight(cv canvas, tf typeface) float64 {
+	return float64(cv.face(tf.font, tf.size).Metrics().Height) / 64
+}
+
+// drawText draws s set in tf at the anchor point (x - w*ax, y + h*ay), where
+// w and h are the width and height of the text, like
+// gg.Context.DrawStringAnchored. Superscript runs are drawn smaller and raised.
+func drawText(cv canvas, tf typeface, s string, x, y, ax, ay float64) {
+	if ac, ok := cv.(anchorCanvas); ok {
+		cv, x, y = ac.anchor(x, y)
+	}
+	w, h := measureText(cv, tf, s)
+	x -= ax * w
+	y += ay * h
+	for _, r := range superscriptRuns(s) {
+		size, dy := tf.size, 0.0
+		if r.superscript {
+			size *= superscriptScale
+			dy = h * 0.4
+		}
+		cv.setFont(tf.font, size)
+		cv.drawString(r.text, x, y-dy)
+		x += float64(font.MeasureString(cv.face(tf.font, size), r.text)) / 64
+	}
+}
+
+// drawTextRotated draws s like drawText, but rotated counterclockwise by
+// angle degrees about the anchor point.
+func drawTextRotated(cv canvas, tf typeface, s string, x, y, ax, ay, angle float64) {
+	if angle == 0 {
+		drawText(cv, tf, s, x, y, ax, ay)
+		return
+	}
+	cv.push()
+	cv.translate(x, y)
+	cv.rotate(-angle * math.Pi / 180)
+	drawText(cv, tf, s, 0, 0, ax, ay)
+	cv.pop()
+}
+
+// rotatedExtent returns the width and height of the bounding box of a w by
+// h box rotated by angle degrees.
+func rotatedExtent(w, h, angle float64) (float64, float64) {
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	sin, cos = math.Abs(sin), math.Abs(cos)
+	return w*cos + h*sin, w*sin + h*cos
+}
+
+type textAlign int
+
+const (
+	alignLeft textAlign = iota
+	alignCenter
+	alignRight
+)
+
+// drawTextWrapped word-wraps s to width and draws the lines at the anchor
+// point, like gg.Context.DrawStringWrapped.
+func drawTextWrapped(cv canvas, tf typeface, s string, x, y, ax, ay, width, lineSpacing float64, align textAlign) {
+	lines := wordWrap(cv, tf, s, width)
+	fh := fontHeight(cv, tf)
+	h := float64(len(lines))*fh*lineSpacing - (lineSpacing-1)*fh
+	x -= ax * width
+	y -= ay * h
+	switch align {
+	case alignLeft:
+		ax = 0
+	case alignCenter:
+		ax = 0.5
+		x += width / 2
+	case alignRight:
+		ax = 1
+		x += width
+	}
+	for _, line := range lines {
+		drawText(cv, tf, line, x, y, ax, 1)
+		y += fh * lineSpacing
+	}
+}
+
+// wrappedHeight returns the height of s when drawn by drawTextWrapped, or
+// zero if s is empty.
+func wrappedHeight(cv canvas, tf typeface, s string, width, lineSpacing float64) float64 {
+	n := float64(len(wordWrap(cv, tf, s, width)))
+	if n == 0 {
+		return 0
+	}
+	fh := fontHeight(cv, tf)
+	return n*fh*lineSpacing - (lineSpacing-1)*fh
+}
+
+// wordWrap splits s into lines no wider than width where possible.
+func wordWrap(cv canvas, tf typeface, s string, width float64) []string {
+	var lines []string
+	for _, para := range strings.Split(s, "\n") {
+		var line string
+		for _, word := range strings.FieldsFunc(para, unicode.IsSpace) {
+			next := word
+			if line != "" {
+				next = line + " " + word
+			}
+			if w, _ := measureText(cv, tf, next); w > width && line != "" {
+				lines = append(lines, line)
+				next = word
+			}
+			line = next
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,121 @@
+package ggg
+
+import (
+	"image/color"
+	"math"
+)
+
+// ColorScale maps numbers between 0 and 1 onto colors.
+type ColorScale func(t float64) color.Color
+
+// missingColor is the color of values that can't be placed on a color
+// scale, like NaN.
+var missingColor color.Color = color.NRGBA{}
+
+// ScaleColor maps values of col continuously onto s, so that i0 maps to the
+// start of s and i1 maps to its end. Values beyond them are clamped. If i0
+// and i1 are equal, values equal to them map to the middle of s. NaN values
+// are transparent. The legend shows the mapping as a colorbar.
+func ScaleColor[I Scalar](col Column[I], i0, i1 I, s ColorScale) Mapping[color.Color] {
+	lo, hi := float64(i0), float64(i1)
+	return Mapping[color.Color]{
+		selector: func(d *Dataset, row int) any {
+			return col.Get(d, row)
+		},
+		scale: func(d *Dataset, row int, _ *Theme) color.Color {
+			v := float64(col.Get(d, row))
+			if math.IsNaN(v) {
+				return missingColor
+			}
+			if lo == hi {
+				// Avoid dividing zero by zero.
+				switch {
+				case v < lo:
+					return s(0)
+				case v > lo:
+					return s(1)
+				}
+				return s(0.5)
+			}
+			t := (v - lo) / (hi - lo)
+			return s(min(max(t, 0), 1))
+		},
+		bar: &colorBar{
+			title: col.Name(),
+			lo:    lo,
+			hi:    hi,
+			kind:  kindOf[I](),
+			scale: s,
+		},
+	}
+}
+
+// colorBar describes a continuous color mapping, for the legend.
+type colorBar struct {
+	title  string
+	lo, hi float64
+	kind   valueKind
+	scale  ColorScale
+}
+
+// label formats x, one end of the colorbar.
+func (b *colorBar) label(x float64) string {
+	a := axis{kind: b.kind}
+	return a.tickLabel(x)
+}
+
+// Sequential returns a color scale that blends from one color to another.
+func Sequential(from, to color.Color) ColorScale {
+	return gradient(from, to)
+}
+
+// Diverging returns a color scale that blends from low to mid in its first
+// half and from mid to high in its second half. It suits values with a
+// meaningful midpoint, such as differences from a baseline, when the
+// midpoint is placed at the center of the scale.
+func Diverging(low, mid, high color.Color) ColorScale {
+	return gradient(low, mid, high)
+}
+
+// Viridis is a perceptually uniform color scale from dark blue through
+// green to yellow, which is readable by people with color blindness and
+// when printed in grayscale.
+var Viridis = gradient(
+	rgb(0x440154), rgb(0x482475), rgb(0x414487), rgb(0x355f8d),
+	rgb(0x2a788e), rgb(0x21918c), rgb(0x22a884), rgb(0x44bf70),
+	rgb(0x7ad151), rgb(0xbddf26), rgb(0xfde725),
+)
+
+// Magma is a perceptually uniform color scale from black through purple
+// and red to pale yellow.
+var Magma = gradient(
+	rgb(0x000004), rgb(0x140e36), rgb(0x3b0f70), rgb(0x641a80),
+	rgb(0x8c2981), rgb(0xb73779), rgb(0xde4968), rgb(0xf7705c),
+	rgb(0xfe9f6d), rgb(0xfecf92), rgb(0xfcfdbf),
+)
+
+// gradient returns a color scale that interpolates linearly between evenly
+// spaced stops. NaN maps to missingColor.
+func gradient(stops ...color.Color) ColorScale {
+	nrgba := make([]color.NRGBA, len(stops))
+	for i, c := range stops {
+		nrgba[i] = color.NRGBAModel.Convert(c).(color.NRGBA)
+	}
+	return func(t float64) color.Color {
+		if math.IsNaN(t) {
+			return missingColor
+		}
+		pos := min(max(t, 0), 1) * float64(len(nrgba)-1)
+		i := min(int(pos), len(nrgba)-2)
+		f := pos - float64(i)
+		a, b := nrgba[i], nrgba[i+1]
+		mix := func(x, y uint8) uint8 {
+			return uint8(math.Round(float64(x)*(1-f) + float64(y)*f))
+		}
+		return color.NRGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), mix(a.A, b.A)}
+	}
+}
+
+func rgb(hex uint32) color.Color {
+	return color.NRGBA{uint8(hex >> 16), uint8(hex >> 8), uint8(hex), 0xff}
+}
@@ -0,0 +1,84 @@
+package ggg
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestGradient(t *testing.T) {
+	black, white := color.NRGBA{0, 0, 0, 0xff}, color.NRGBA{0xff, 0xff, 0xff, 0xff}
+	red := color.NRGBA{0xff, 0, 0, 0xff}
+	type test struct {
+		name  string
+		scale ColorScale
+		t     float64
+		want  color.Color
+	}
+	for _, ts := range []test{
+		{name: "Start", scale: Sequential(black, white), t: 0, want: black},
+		{name: "End", scale: Sequential(black, white), t: 1, want: white},
+		{name: "Middle", scale: Sequential(black, white), t: 0.5, want: color.NRGBA{0x80, 0x80, 0x80, 0xff}},
+		{name: "Below", scale: Sequential(black, white), t: -1, want: black},
+		{name: "Above", scale: Sequential(black, white), t: 2, want: white},
+		{name: "NaN", scale: Sequential(black, white), t: math.NaN(), want: missingColor},
+		{name: "DivergingMid", scale: Diverging(black, red, white), t: 0.5, want: red},
+		{name: "DivergingQuarter", scale: Diverging(black, red, white), t: 0.75, want: color.NRGBA{0xff, 0x80, 0x80, 0xff}},
+		{name: "ViridisStart", scale: Viridis, t: 0, want: rgb(0x440154)},
+		{name: "MagmaEnd", scale: Magma, t: 1, want: rgb(0xfcfdbf)},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			if got := ts.scale(ts.t); got != ts.want {
+				t.Errorf("got %v at %v, want %v", got, ts.t, ts.want)
+			}
+		})
+	}
+}
+
+func TestScaleColor(t *testing.T) {
+	col := NewColumn[float64]("v")
+	d := Empty()
+	d.AddColumn(col)
+	values := []float64{-1, 0, 5, 10, 20, math.NaN()}
+	for _, v := range values {
+		for row := range d.Grow(1) {
+			col.Set(d, row, v)
+		}
+	}
+	black, white := color.NRGBA{0, 0, 0, 0xff}, color.NRGBA{0xff, 0xff, 0xff, 0xff}
+	gray := color.NRGBA{0x80, 0x80, 0x80, 0xff}
+	type test struct {
+		name   string
+		lo, hi float64
+		want   []color.Color
+	}
+	for _, ts := range []test{
+		{
+			name: "Range",
+			lo:   0,
+			hi:   10,
+			want: []color.Color{black, black, gray, white, white, missingColor},
+		},
+		{
+			name: "Reversed",
+			lo:   10,
+			hi:   0,
+			want: []color.Color{white, white, gray, black, black, missingColor},
+		},
+		{
+			name: "Empty",
+			lo:   5,
+			hi:   5,
+			want: []color.Color{black, black, gray, white, white, missingColor},
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			m := ScaleColor(col, ts.lo, ts.hi, Sequential(black, white))
+			for row, want := range ts.want {
+				if got := m.scale(d, row, nil); got != want {
+					t.Errorf("got %v for %v, want %v", got, values[row], want)
+				}
+			}
+		})
+	}
+}
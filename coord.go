@@ -0,0 +1,135 @@
+package ggg
+
+import "math"
+
+// coordKind is a coordinate system, which decides where positions along
+// the X and Y axes are placed on the plot.
+type coordKind int
+
+const (
+	coordCartesian coordKind = iota
+	coordFlip
+	coordPolar
+)
+
+// CoordFlip swaps the axes of the plot, so that the X axis runs up the left
+// side and the Y axis along the bottom. Bars become horizontal, which suits
+// categories with long names.
+func CoordFlip() PresentationOption {
+	return func(opts *presentOpts) {
+		opts.coord = coordFlip
+	}
+}
+
+// CoordPolar places positions along the X axis at an angle, clockwise from
+// the top of the plot, and positions along the Y axis at a distance from
+// its center. The X axis goes once around the plot. Bars become wedges,
+// for rose charts, and lines curve around the center, for radar charts.
+// Spans become arcs, for pie and donut charts like those made by PiePlot.
+//
+// The secondary Y axis can't be used with polar coordinates.
+func CoordPolar() PresentationOption {
+	return func(opts *presentOpts) {
+		opts.coord = coordPolar
+	}
+}
+
+// polarStep is the longest straight line drawn in polar coordinates, in
+// pixels before transforming, so that lines appear to curve.
+const polarStep = 4
+
+// coordCanvas is a canvas that transforms coordinates before drawing onto
+// another. Layers draw onto it as if the plot were Cartesian. If curved is
+// set, lines are drawn in short steps, so that they follow the transform.
+type coordCanvas struct {
+	canvas
+	to     func(x, y float64) (float64, float64)
+	curved bool
+
+	// start and cur are the start of the current subpath and the current
+	// point, before transforming.
+	start, cur [2]float64
+}
+
+// newCoordCanvas returns a canvas that transforms coordinates with to before
+// drawing onto c.
+func newCoordCanvas(c canvas, to func(x, y float64) (float64, float64), curved bool) canvas {
+	cc := &coordCanvas{canvas: c, to: to, curved: curved}
+	if rec, ok := c.(pointRecorder); ok {
+		return &recordingCoordCanvas{cc, rec}
+	}
+	return cc
+}
+
+func (cc *coordCanvas) moveTo(x, y float64) {
+	cc.start, cc.cur = [2]float64{x, y}, [2]float64{x, y}
+	cc.canvas.moveTo(cc.to(x, y))
+}
+
+func (cc *coordCanvas) lineTo(x, y float64) {
+	n := 1
+	if cc.curved {
+		n = max(1, int(math.Ceil(math.Hypot(x-cc.cur[0], y-cc.cur[1])/polarStep)))
+	}
+	x0, y0 := cc.cur[0], cc.cur[1]
+	for i := 1; i <= n; i++ {
+		t := float64(i) / float64(n)
+		cc.canvas.lineTo(cc.to(x0+(x-x0)*t, y0+(y-y0)*t))
+	}
+	cc.cur = [2]float64{x, y}
+}
+
+func (cc *coordCanvas) closePath() {
+	cc.lineTo(cc.start[0], cc.start[1])
+	cc.canvas.closePath()
+}
+
+func (cc *coordCanvas) rect(x, y, w, h float64) {
+	cc.moveTo(x, y)
+	cc.lineTo(x+w, y)
+	cc.lineTo(x+w, y+h)
+	cc.lineTo(x, y+h)
+	cc.closePath()
+}
+
+func (cc *coordCanvas) circle(x, y, r float64) {
+	tx, ty := cc.to(x, y)
+	cc.canvas.circle(tx, ty, r)
+}
+
+func (cc *coordCanvas) drawString(s string, x, y float64) {
+	tx, ty := cc.to(x, y)
+	cc.canvas.drawString(s, tx, ty)
+}
+
+// anchor transforms the anchor point of text, which is then drawn upright
+// onto the returned canvas.
+func (cc *coordCanvas) anchor(x, y float64) (canvas, float64, float64) {
+	tx, ty := cc.to(x, y)
+	return cc.canvas, tx, ty
+}
+
+func (cc *coordCanvas) beginGroup(class, series string) {
+	beginGroup(cc.canvas, class, series)
+}
+
+func (cc *coordCanvas) endGroup() {
+	endGroup(cc.canvas)
+}
+
+// recordingCoordCanvas is a coordCanvas for a canvas that records points.
+type recordingCoordCanvas struct {
+	*coordCanvas
+	rec pointRecorder
+}
+
+func (rc *recordingCoordCanvas) recordPoint(series string, x, y float64, fields []field) {
+	tx, ty := rc.to(x, y)
+	rc.rec.recordPoint(series, tx, ty, fields)
+}
+
+// anchorCanvas is implemented by canvases that transform coordinates, so
+// that text can be placed by its anchor point and still be drawn upright.
+type anchorCanvas interface {
+	anchor(x, y float64) (canvas, float64, float64)
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"io"
 	"iter"
+	"maps"
 	"reflect"
+	"slices"
 	"strconv"
 	"text/tabwriter"
 	"unique"
@@ -36,10 +38,15 @@ func (c *columnData[T]) get(r int) any {
 	return c.values[r]
 }
 
+func (c *columnData[T]) clone() columnI {
+	return &columnData[T]{name: c.name, key: c.key, values: slices.Clone(c.values)}
+}
+
 type columnI interface {
 	id() string
 	grow(n int)
 	get(r int) any
+	clone() columnI
 }
 
 // Empty returns an empty dataset.
@@ -47,6 +54,16 @@ func Empty() *Dataset {
 	return &Dataset{colMap: make(map[unique.Handle[columnKey]]int)}
 }
 
+// clone returns a copy of d, which can be changed without changing d.
+// Columns of d are found in the copy at the same place.
+func (d *Dataset) clone() *Dataset {
+	c := &Dataset{rows: d.rows, colMap: maps.Clone(d.colMap)}
+	for _, col := range d.columns {
+		c.columns = append(c.columns, col.clone())
+	}
+	return c
+}
+
 // Print dumps out a summary of the dataset in a nicely-formatted manner to w.
 func (d *Dataset) Print(w io.Writer) error {
 	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
@@ -96,6 +113,15 @@ func (d *Dataset) Print(w io.Writer) error {
 	return tw.Flush()
 }
 
+// rowFields returns the names and formatted values of each column in a row.
+func (d *Dataset) rowFields(row int) []field {
+	fields := make([]field, 0, len(d.columns))
+	for _, c := range d.columns {
+		fields = append(fields, field{c.id(), fmt.Sprintf("%v", c.get(row))})
+	}
+	return fields
+}
+
 // Filter returns an iterator over all rows that are accepted by the provided filter.
 func (d *Dataset) Filter(f Filter) iter.Seq[int] {
 	return func(yield func(int) bool) {
@@ -312,6 +338,28 @@ func ConvertFunc[T, S any](d *Dataset, c Column[T], conv func(T) (S, error)) (Co
 	return cs, nil
 }
 
+// Categorize assigns each distinct value of c a position along an axis, in
+// order of first appearance, so that it can be plotted. It adds a column
+// holding the position of each row's value to the dataset, and returns it
+// along with the names of the values, for Categories.
+func Categorize[T comparable](d *Dataset, c Column[T]) (Column[int], []string) {
+	pos := NewColumn[int](c.Name() + " position")
+	d.AddColumn(pos)
+	index := make(map[T]int)
+	var names []string
+	for i := 0; i < d.Rows(); i++ {
+		v := c.Get(d, i)
+		p, ok := index[v]
+		if !ok {
+			p = len(names)
+			index[v] = p
+			names = append(names, fmt.Sprint(v))
+		}
+		pos.Set(d, i, p)
+	}
+	return pos, names
+}
+
 func (d *Dataset) ParseInt(c Column[string]) (Column[int64], error) {
 	return ConvertFunc(d, c, func(s string) (int64, error) {
 		return strconv.ParseInt(s, 10, 64)
@@ -0,0 +1,281 @@
+package ggg
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+
+	"github.com/aclements/go-moremath/stats"
+)
+
+// Density is a layer that draws a kernel density estimate of the
+// distribution of the values in a column, a smooth alternative to a
+// histogram.
+//
+// With the Line geom, the estimate is drawn as a curve, with the values
+// along the X axis and their density along the Y axis. Rows are split into
+// series by the Geom's color and size.
+//
+// With the Violin geom, there is an estimate for each distinct value of
+// Position, which places it along the X axis. The values are along the Y
+// axis, and the estimate is mirrored on either side of its position.
+type Density[P Value, T Scalar] struct {
+	Data      *Dataset
+	Values    Column[T]
+	Position  Column[P]
+	Bandwidth Bandwidth
+	Geom      *Geom
+
+	// filter, if not nil, restricts the rows that are used.
+	filter Filter
+
+	// prepared is set if series holds the estimates, as computed by
+	// prepare.
+	prepared bool
+	series   []*densitySeries
+}
+
+// densityPoints is the number of points at which each estimate is
+// evaluated.
+const densityPoints = 200
+
+// densitySeries is the estimate for one series or violin.
+type densitySeries struct {
+	row    int     // A representative row, for the series' color and size.
+	pos    float64 // Position along the X axis, for violins.
+	kde    stats.KDE
+	lo, hi float64 // Range over which to evaluate the estimate.
+	xs, ys []float64
+}
+
+func (d *Density[P, T]) violin() bool {
+	return d.Geom != nil && d.Geom.kind == kindViolin
+}
+
+// estimates splits the layer's rows into series and estimates the
+// distribution of each.
+func (d *Density[P, T]) estimates() []*densitySeries {
+	if d.prepared {
+		return d.series
+	}
+	if d.Geom == nil {
+		return nil
+	}
+	smap := make(map[any]*densitySeries)
+	var ss []*densitySeries
+	for row := range filteredRows(d.Data, d.filter) {
+		v := toFloat(d.Values.Get(d.Data, row))
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		key := d.Geom.grouping(d.Data, row)
+		var pos float64
+		if d.violin() {
+			pos = toFloat(d.Position.Get(d.Data, row))
+			key = any2{key, pos}
+		}
+		s, ok := smap[key]
+		if !ok {
+			s = &densitySeries{row: row, pos: pos}
+			smap[key] = s
+			ss = append(ss, s)
+		}
+		s.kde.Sample.Xs = append(s.kde.Sample.Xs, v)
+	}
+	for _, s := range ss {
+		sample := &s.kde.Sample
+		slices.Sort(sample.Xs)
+		sample.Sorted = true
+		s.kde.Kernel = stats.GaussianKernel
+		s.kde.Bandwidth = d.Bandwidth.bandwidth(*sample)
+		if s.kde.Bandwidth == 0 || math.IsNaN(s.kde.Bandwidth) {
+			// All the values are the same, or there's only one.
+			s.kde.Bandwidth = max(math.Abs(sample.Xs[0])*0.1, 1e-9)
+		}
+		s.lo, s.hi = sample.Xs[0], sample.Xs[len(sample.Xs)-1]
+		if !d.violin() {
+			// Show the tails of the estimate. Violins are trimmed to
+			// the range of the data instead.
+			s.lo -= 3 * s.kde.Bandwidth
+			s.hi += 3 * s.kde.Bandwidth
+		}
+		s.xs = make([]float64, densityPoints)
+		s.ys = make([]float64, densityPoints)
+		for i := range densityPoints {
+			x := s.lo + (s.hi-s.lo)*float64(i)/(densityPoints-1)
+			s.xs[i], s.ys[i] = x, s.kde.PDF(x)
+		}
+	}
+	return ss
+}
+
+func (d *Density[P, T]) prepare() AnyLayer {
+	c := *d
+	c.series = d.estimates()
+	c.prepared = true
+	return &c
+}
+
+func (d *Density[P, T]) xRange() (lo, hi float64) {
+	if d.violin() {
+		lo, hi = colRange(d.Data, d.Position)
+		w := d.Geom.maxSize(d.Data)
+		return lo - w/2, hi + w/2
+	}
+	lo, hi = math.Inf(1), math.Inf(-1)
+	for _, s := range d.estimates() {
+		lo, hi = min(lo, s.lo), max(hi, s.hi)
+	}
+	if lo > hi {
+		return 0, 0
+	}
+	return lo, hi
+}
+
+func (d *Density[P, T]) yRange() (lo, hi float64) {
+	if d.violin() {
+		return colRange(d.Data, d.Values)
+	}
+	for _, s := range d.estimates() {
+		hi = max(hi, slices.Max(s.ys))
+	}
+	return 0, hi
+}
+
+func (d *Density[P, T]) xKind() valueKind {
+	if d.violin() {
+		return kindOf[P]()
+	}
+	return kindOf[T]()
+}
+
+func (d *Density[P, T]) yKind() valueKind {
+	if d.violin() {
+		return kindOf[T]()
+	}
+	return valueNumber
+}
+
+func (d *Density[P, T]) legend(theme *Theme) []legendEntry {
+	if d.Geom == nil || d.Data == nil {
+		return nil
+	}
+	return d.Geom.legend(d.Data, theme)
+}
+
+func (d *Density[P, T]) dataset() *Dataset {
+	return d.Data
+}
+
+// where returns a copy of the layer that only uses rows accepted by f.
+func (d *Density[P, T]) where(f Filter) AnyLayer {
+	c := *d
+	if c.filter != nil {
+		f = And(c.filter, f)
+	}
+	c.filter = f
+	c.prepared, c.series = false, nil
+	return &c
+}
+
+func (d *Density[P, T]) render(c canvas, theme *Theme, xScale, yScale scaleFunc, scaleFactor float64) error {
+	if d.Geom == nil || d.Geom.kind == kindBadGeom {
+		return fmt.Errorf("no initialized Geom for density")
+	}
+	if d.Data == nil {
+		return fmt.Errorf("no intended dataset specified for density")
+	}
+	if !d.Values.Valid() {
+		return fmt.Errorf("no initialized Values column for density")
+	}
+	if d.violin() && !d.Position.Valid() {
+		return fmt.Errorf("no initialized Position column for violin")
+	}
+	if d.Geom.kind != kindLine && d.Geom.kind != kindViolin {
+		return fmt.Errorf("density can only be drawn with the Line or Violin geoms")
+	}
+
+	rec, _ := c.(pointRecorder)
+	for _, s := range d.estimates() {
+		label := d.Geom.seriesLabel(d.Data, s.row)
+		beginGroup(c, "series", label)
+		c.setColor(d.Geom.color.scale(d.Data, s.row, theme))
+		if d.violin() {
+			// Scale the estimate so that the violin is at its widest
+			// where the density is highest.
+			half := d.Geom.size.scale(d.Data, s.row, theme) / 2 / slices.Max(s.ys)
+			c.moveTo(xScale(s.pos), yScale(s.xs[0]))
+			for i := range s.xs {
+				c.lineTo(xScale(s.pos+s.ys[i]*half), yScale(s.xs[i]))
+			}
+			for i := len(s.xs) - 1; i >= 0; i-- {
+				c.lineTo(xScale(s.pos-s.ys[i]*half), yScale(s.xs[i]))
+			}
+			c.closePath()
+			c.fill()
+			if rec != nil {
+				mid := s.kde.Sample.Xs[len(s.kde.Sample.Xs)/2]
+				rec.recordPoint(label, xScale(s.pos), yScale(mid), []field{
+					{d.Position.Name(), fmt.Sprintf("%v", d.Position.Get(d.Data, s.row))},
+					{"median", strconv.FormatFloat(mid, 'g', 6, 64)},
+					{"bandwidth", strconv.FormatFloat(s.kde.Bandwidth, 'g', 6, 64)},
+					{"n", strconv.Itoa(len(s.kde.Sample.Xs))},
+				})
+			}
+		} else {
+			c.moveTo(xScale(s.xs[0]), yScale(s.ys[0]))
+			for i := range s.xs[1:] {
+				c.lineTo(xScale(s.xs[i+1]), yScale(s.ys[i+1]))
+			}
+			c.setLineWidth(scaleFactor * d.Geom.size.scale(d.Data, s.row, theme))
+			c.stroke()
+			if rec != nil {
+				for i := range s.xs {
+					rec.recordPoint(label, xScale(s.xs[i]), yScale(s.ys[i]), []field{
+						{d.Values.Name(), strconv.FormatFloat(s.xs[i], 'g', 6, 64)},
+						{"density", strconv.FormatFloat(s.ys[i], 'g', 6, 64)},
+					})
+				}
+			}
+		}
+		endGroup(c)
+	}
+	return nil
+}
+
+// Bandwidth decides the bandwidth of a kernel density estimate, which
+// controls how smooth it is. The zero Bandwidth uses Scott's rule.
+type Bandwidth struct {
+	f func(stats.Sample) float64
+}
+
+func (b Bandwidth) bandwidth(s stats.Sample) float64 {
+	if b.f == nil {
+		return stats.BandwidthScott(s)
+	}
+	return b.f(s)
+}
+
+// Silverman picks the bandwidth using Silverman's rule of thumb. It assumes
+// the values are roughly normal, and oversmooths otherwise.
+func Silverman() Bandwidth {
+	return Bandwidth{func(s stats.Sample) float64 {
+		return stats.BandwidthSilverman(s)
+	}}
+}
+
+// Scott picks the bandwidth using Scott's rule, which is more robust to
+// outliers than Silverman's.
+func Scott() Bandwidth {
+	return Bandwidth{func(s stats.Sample) float64 {
+		return stats.BandwidthScott(s)
+	}}
+}
+
+// FixedBandwidth uses a bandwidth of h, in the units of the values.
+func FixedBandwidth(h float64) Bandwidth {
+	return Bandwidth{func(stats.Sample) float64 {
+		return h
+	}}
+}
@@ -0,0 +1,161 @@
+package ggg
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestDensityEstimates(t *testing.T) {
+	colV := NewColumn[float64]("v")
+	colS := NewColumn[string]("s")
+	colP := NewColumn[int]("p")
+	d := Empty()
+	d.AddColumn(colV)
+	d.AddColumn(colS)
+	d.AddColumn(colP)
+	add := func(s string, p int, vs ...float64) {
+		for _, v := range vs {
+			for row := range d.Grow(1) {
+				colV.Set(d, row, v)
+				colS.Set(d, row, s)
+				colP.Set(d, row, p)
+			}
+		}
+	}
+	a, b := []float64{1, 2, 2, 3, 3, 3, 4, 4, 5}, []float64{10, 20}
+	add("a", 0, a...)
+	add("b", 1, b...)
+	add("c", 2, 7)
+
+	type test struct {
+		name      string
+		geom      *Geom
+		bandwidth Bandwidth
+		// want holds, for each series, its range and bandwidth.
+		want [][3]float64
+	}
+	for _, ts := range []test{
+		{
+			name:      "Line",
+			geom:      Line(NiceColors(colS), Constant(1.0)),
+			bandwidth: FixedBandwidth(0.5),
+			want: [][3]float64{
+				{-0.5, 6.5, 0.5},
+				{8.5, 21.5, 0.5},
+				{5.5, 8.5, 0.5},
+			},
+		},
+		{
+			// A lone value falls back to a tenth of its magnitude.
+			name:      "Scott",
+			geom:      Line(NiceColors(colS), Constant(1.0)),
+			bandwidth: Scott(),
+			want: [][3]float64{
+				{1 - 3*silverman(a...), 5 + 3*silverman(a...), silverman(a...)},
+				{10 - 3*silverman(b...), 20 + 3*silverman(b...), silverman(b...)},
+				{7 - 3*0.7, 7 + 3*0.7, 0.7},
+			},
+		},
+		{
+			// Violins are trimmed to the range of their values.
+			name:      "Violin",
+			geom:      Violin(Constant[color.Color](nil), Constant(0.8)),
+			bandwidth: Silverman(),
+			want: [][3]float64{
+				{1, 5, silverman(a...)},
+				{10, 20, silverman(b...)},
+				{7, 7, 0.7},
+			},
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			l := &Density[int, float64]{Data: d, Values: colV, Position: colP, Bandwidth: ts.bandwidth, Geom: ts.geom}
+			ss := l.estimates()
+			if len(ss) != len(ts.want) {
+				t.Fatalf("got %d series, want %d", len(ss), len(ts.want))
+			}
+			for i, s := range ss {
+				got := [3]float64{s.lo, s.hi, s.kde.Bandwidth}
+				for j := range got {
+					if math.Abs(got[j]-ts.want[i][j]) > 1e-9 {
+						t.Errorf("series %d: got range [%v, %v] and bandwidth %v, want %v", i, got[0], got[1], got[2], ts.want[i])
+						break
+					}
+				}
+				if l.violin() {
+					if s.pos != float64(i) {
+						t.Errorf("series %d: got position %v, want %d", i, s.pos, i)
+					}
+					continue
+				}
+				// The tails cover almost all of the estimate.
+				if area := trapezoid(s.xs, s.ys); math.Abs(area-1) > 0.01 {
+					t.Errorf("series %d: estimate has area %v, want 1", i, area)
+				}
+			}
+		})
+	}
+}
+
+func TestDensityPrepare(t *testing.T) {
+	colV := NewColumn[float64]("v")
+	colS := NewColumn[string]("s")
+	d := Empty()
+	d.AddColumn(colV)
+	d.AddColumn(colS)
+	for row := range d.Grow(6) {
+		colV.Set(d, row, float64(row))
+		colS.Set(d, row, []string{"a", "b"}[row%2])
+	}
+	l := &Density[int, float64]{Data: d, Values: colV, Geom: Line(NiceColors(colS), Constant(1.0))}
+
+	// A prepared density keeps its estimates, rather than computing them
+	// again for each of its ranges and for drawing.
+	p := l.prepare().(*Density[int, float64])
+	ss := p.estimates()
+	if len(ss) != 2 {
+		t.Fatalf("got %d series, want 2", len(ss))
+	}
+	colV.Set(d, 0, 100)
+	if got := p.estimates(); len(got) != 2 || got[0] != ss[0] || got[1] != ss[1] {
+		t.Errorf("prepared density computed its estimates again")
+	}
+	if lo, hi := p.xRange(); hi >= 100 {
+		t.Errorf("got X range [%v, %v], want one from the prepared estimates", lo, hi)
+	}
+
+	// Filtering a prepared density estimates the filtered rows.
+	f := p.where(EqualTo(colS, "b")).(*Density[int, float64])
+	if got := f.estimates(); len(got) != 1 || got[0] == ss[1] {
+		t.Errorf("filtered density didn't compute its estimates again")
+	}
+}
+
+// silverman computes the bandwidth for the values xs by Silverman's rule
+// of thumb. Scott's rule agrees with it for samples without outliers, like
+// those in the tests.
+func silverman(xs ...float64) float64 {
+	return 1.06 * stddev(xs) * math.Pow(float64(len(xs)), -1.0/5)
+}
+
+// stddev returns the sample standard deviation of xs.
+func stddev(xs []float64) float64 {
+	var mean, sq float64
+	for _, x := range xs {
+		mean += x / float64(len(xs))
+	}
+	for _, x := range xs {
+		sq += (x - mean) * (x - mean)
+	}
+	return math.Sqrt(sq / float64(len(xs)-1))
+}
+
+// trapezoid integrates the function through the points (xs[i], ys[i]).
+func trapezoid(xs, ys []float64) float64 {
+	var area float64
+	for i := 1; i < len(xs); i++ {
+		area += (xs[i] - xs[i-1]) * (ys[i] + ys[i-1]) / 2
+	}
+	return area
+}
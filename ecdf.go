@@ -0,0 +1,146 @@
+package ggg
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// ECDF is a layer that draws the empirical cumulative distribution of the
+// values in a column: for each value along the X axis, the fraction of
+// values no greater than it along the Y axis. Rows are split into series by
+// the Geom's color and size.
+//
+// The distribution is best drawn with the Step geom, which rises at each
+// value. It may also be drawn with Line or Point.
+type ECDF[T Scalar] struct {
+	Data *Dataset
+	X    Column[T]
+	Geom *Geom
+
+	// filter, if not nil, restricts the rows that are used.
+	filter Filter
+}
+
+// ecdfSeries is the distribution of one series.
+type ecdfSeries struct {
+	row    int       // A representative row, for the series' color and size.
+	xs, ys []float64 // Distinct values and the fraction no greater than each.
+	counts []int     // Number of values no greater than each of xs.
+}
+
+// distributions splits the layer's rows into series and computes the
+// distribution of each.
+func (e *ECDF[T]) distributions() []*ecdfSeries {
+	if e.Geom == nil {
+		return nil
+	}
+	smap := make(map[any]*ecdfSeries)
+	var ss []*ecdfSeries
+	for row := range filteredRows(e.Data, e.filter) {
+		v := toFloat(e.X.Get(e.Data, row))
+		if math.IsNaN(v) {
+			continue
+		}
+		key := e.Geom.grouping(e.Data, row)
+		s, ok := smap[key]
+		if !ok {
+			s = &ecdfSeries{row: row}
+			smap[key] = s
+			ss = append(ss, s)
+		}
+		s.xs = append(s.xs, v)
+	}
+	for _, s := range ss {
+		values := s.xs
+		slices.Sort(values)
+		s.xs = nil
+		for i, v := range values {
+			if i+1 < len(values) && values[i+1] == v {
+				continue
+			}
+			s.xs = append(s.xs, v)
+			s.ys = append(s.ys, float64(i+1)/float64(len(values)))
+			s.counts = append(s.counts, i+1)
+		}
+	}
+	return ss
+}
+
+func (e *ECDF[T]) xRange() (lo, hi float64) {
+	return colRange(e.Data, e.X)
+}
+
+func (e *ECDF[T]) yRange() (lo, hi float64) {
+	return 0, 1
+}
+
+func (e *ECDF[T]) xKind() valueKind {
+	return kindOf[T]()
+}
+
+func (e *ECDF[T]) yKind() valueKind {
+	return valueNumber
+}
+
+func (e *ECDF[T]) legend(theme *Theme) []legendEntry {
+	if e.Geom == nil || e.Data == nil {
+		return nil
+	}
+	return e.Geom.legend(e.Data, theme)
+}
+
+func (e *ECDF[T]) dataset() *Dataset {
+	return e.Data
+}
+
+// where returns a copy of the layer that only uses rows accepted by f.
+func (e *ECDF[T]) where(f Filter) AnyLayer {
+	c := *e
+	if c.filter != nil {
+		f = And(c.filter, f)
+	}
+	c.filter = f
+	return &c
+}
+
+func (e *ECDF[T]) render(c canvas, theme *Theme, xScale, yScale scaleFunc, scaleFactor float64) error {
+	if e.Geom == nil || e.Geom.kind == kindBadGeom {
+		return fmt.Errorf("no initialized Geom for ECDF")
+	}
+	if e.Data == nil {
+		return fmt.Errorf("no intended dataset specified for ECDF")
+	}
+	if !e.X.Valid() {
+		return fmt.Errorf("no initialized X column for ECDF")
+	}
+	if e.Geom.kind != kindStep && e.Geom.kind != kindLine && e.Geom.kind != kindPoint {
+		return fmt.Errorf("ECDF can only be drawn with the Step, Line or Point geoms")
+	}
+
+	rec, _ := c.(pointRecorder)
+	for _, s := range e.distributions() {
+		label := e.Geom.seriesLabel(e.Data, s.row)
+		beginGroup(c, "series", label)
+		draw, _ := e.Geom.drawer(theme, c, xScale, yScale, scaleFactor, nil)
+		if e.Geom.kind != kindPoint {
+			// Rise from zero at the smallest value.
+			draw(e.Data, s.row, s.xs[0], []float64{0})
+		}
+		for i := range s.xs {
+			draw(e.Data, s.row, s.xs[i], s.ys[i:i+1])
+		}
+		if rec != nil {
+			for i := range s.xs {
+				rec.recordPoint(label, xScale(s.xs[i]), yScale(s.ys[i]), []field{
+					{e.X.Name(), strconv.FormatFloat(s.xs[i], 'g', 6, 64)},
+					{"cumulative", strconv.FormatFloat(s.ys[i], 'g', 6, 64)},
+					{"n", strconv.Itoa(s.counts[i])},
+				})
+			}
+		}
+		endGroup(c)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package ggg
+
+import (
+	"image/color"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestECDFSteps(t *testing.T) {
+	type series struct {
+		xs, ys []float64
+		counts []int
+	}
+	type test struct {
+		name   string
+		values []float64
+		groups []string
+		want   []series
+	}
+	for _, ts := range []test{
+		{
+			name:   "Empty",
+			values: nil,
+			want:   nil,
+		},
+		{
+			name:   "Distinct",
+			values: []float64{3, 1, 2, 4},
+			want: []series{
+				{[]float64{1, 2, 3, 4}, []float64{0.25, 0.5, 0.75, 1}, []int{1, 2, 3, 4}},
+			},
+		},
+		{
+			// Ties make a single, taller step.
+			name:   "Ties",
+			values: []float64{2, 1, 2, 2},
+			want: []series{
+				{[]float64{1, 2}, []float64{0.25, 1}, []int{1, 4}},
+			},
+		},
+		{
+			name:   "NaN",
+			values: []float64{math.NaN(), 5, math.NaN(), 6},
+			want: []series{
+				{[]float64{5, 6}, []float64{0.5, 1}, []int{1, 2}},
+			},
+		},
+		{
+			name:   "Series",
+			values: []float64{1, 10, 2, 20, 3},
+			groups: []string{"a", "b", "a", "b", "a"},
+			want: []series{
+				{[]float64{1, 2, 3}, []float64{1.0 / 3, 2.0 / 3, 1}, []int{1, 2, 3}},
+				{[]float64{10, 20}, []float64{0.5, 1}, []int{1, 2}},
+			},
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			colX := NewColumn[float64]("x")
+			colS := NewColumn[string]("s")
+			d := Empty()
+			d.AddColumn(colX)
+			d.AddColumn(colS)
+			for i, v := range ts.values {
+				for row := range d.Grow(1) {
+					colX.Set(d, row, v)
+					if ts.groups != nil {
+						colS.Set(d, row, ts.groups[i])
+					}
+				}
+			}
+			e := &ECDF[float64]{Data: d, X: colX, Geom: Step(NiceColors(colS), Constant(1.0))}
+			ss := e.distributions()
+			if len(ss) != len(ts.want) {
+				t.Fatalf("got %d series, want %d", len(ss), len(ts.want))
+			}
+			for i, s := range ss {
+				w := ts.want[i]
+				if !slices.Equal(s.xs, w.xs) || !slices.Equal(s.ys, w.ys) || !slices.Equal(s.counts, w.counts) {
+					t.Errorf("series %d: got steps at %v to %v (%v), want %v to %v (%v)", i, s.xs, s.ys, s.counts, w.xs, w.ys, w.counts)
+				}
+			}
+		})
+	}
+}
+
+func TestECDFNoGeom(t *testing.T) {
+	colX := NewColumn[float64]("x")
+	d := Empty()
+	d.AddColumn(colX)
+	for row := range d.Grow(1) {
+		colX.Set(d, row, 1)
+	}
+	e := &ECDF[float64]{Data: d, X: colX}
+	if ss := e.distributions(); ss != nil {
+		t.Errorf("got %d series without a Geom, want none", len(ss))
+	}
+	e.Geom = Bar(Constant[color.Color](nil), Constant(1.0))
+	if err := e.render(nil, nil, nil, nil, 1); err == nil {
+		t.Errorf("rendering with the Bar geom succeeded, want an error")
+	}
+}
@@ -0,0 +1,265 @@
+package ggg
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"math"
+)
+
+// Figure arranges several plots in a grid, to be rendered together.
+type Figure struct {
+	title       string
+	cells       []figureCell
+	rowHeights  []float64
+	colWidths   []float64
+	shareX      bool
+	shareY      bool
+	shareLegend bool
+}
+
+type figureCell struct {
+	row, col int
+	plot     *Plot
+}
+
+// NewFigure returns an empty figure, configured by opts.
+func NewFigure(opts ...FigureOption) *Figure {
+	f := &Figure{}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// Place puts p in the cell at row and col of the figure's grid, which grows
+// to fit. Cells are numbered from zero, starting at the top left, and
+// rendering fails if row or col is negative.
+func (f *Figure) Place(row, col int, p *Plot) *Figure {
+	f.cells = append(f.cells, figureCell{row, col, p})
+	return f
+}
+
+// FigureOption configures a figure.
+type FigureOption func(*Figure)
+
+// FigureTitle sets a title drawn above all the plots.
+func FigureTitle(title string) FigureOption {
+	return func(f *Figure) {
+		f.title = title
+	}
+}
+
+// RowHeights sets the heights of the rows of the grid relative to each
+// other. Rows without a height have a height of 1.
+func RowHeights(heights ...float64) FigureOption {
+	return func(f *Figure) {
+		f.rowHeights = heights
+	}
+}
+
+// ColumnWidths sets the widths of the columns of the grid relative to each
+// other. Columns without a width have a width of 1.
+func ColumnWidths(widths ...float64) FigureOption {
+	return func(f *Figure) {
+		f.colWidths = widths
+	}
+}
+
+// ShareX gives all the plots the same X axis limits, which cover all their
+// data. Only the bottom plot in each column keeps its X axis title.
+func ShareX() FigureOption {
+	return func(f *Figure) {
+		f.shareX = true
+	}
+}
+
+// ShareY gives all the plots the same Y axis limits, which cover all their
+// data. Only the leftmost plot in each row keeps its Y axis title.
+func ShareY() FigureOption {
+	return func(f *Figure) {
+		f.shareY = true
+	}
+}
+
+// ShareLegend replaces the plots' legends with a single legend below them.
+func ShareLegend() FigureOption {
+	return func(f *Figure) {
+		f.shareLegend = true
+	}
+}
+
+// Render renders the figure as an image of the provided size.
+func (f *Figure) Render(theme string, width, height int) (image.Image, error) {
+	th, err := lookupTheme(theme)
+	if err != nil {
+		return nil, err
+	}
+	rc := newRasterCanvas(width, height)
+	if err := f.draw(rc, th, float64(width), float64(height)); err != nil {
+		return nil, err
+	}
+	return rc.image(), nil
+}
+
+// RenderSVG renders the figure as an SVG document of the provided size and
+// writes it to w.
+func (f *Figure) RenderSVG(w io.Writer, theme string, width, height int) error {
+	th, err := lookupTheme(theme)
+	if err != nil {
+		return err
+	}
+	sc := newSVGCanvas(w, width, height)
+	if err := f.draw(sc, th, float64(width), float64(height)); err != nil {
+		return err
+	}
+	return sc.finish()
+}
+
+// RenderPDF renders the figure as a single-page PDF document of the
+// provided size in points and writes it to w.
+func (f *Figure) RenderPDF(w io.Writer, theme string, width, height int) error {
+	th, err := lookupTheme(theme)
+	if err != nil {
+		return err
+	}
+	pc := newPDFCanvas(width, height)
+	if err := f.draw(pc, th, float64(width), float64(height)); err != nil {
+		return err
+	}
+	return pc.finish(w)
+}
+
+// draw draws the figure onto c, filling a w by h area at the origin. Each
+// plot is drawn as if it were alone on a canvas the size of its cell.
+func (f *Figure) draw(c canvas, th *Theme, w, h float64) error {
+	c.rect(0, 0, w, h)
+	c.setColor(th.BorderBackgroundColor)
+	c.fill()
+
+	// Reserve space for the title and legend.
+	top, bottom := 0.0, h
+	if f.title != "" {
+		titleFont := typeface{th.TitleFont, math.Round(h / 20)}
+		fh := fontHeight(c, titleFont)
+		c.setColor(th.ForegroundColor)
+		drawText(c, titleFont, f.title, fh/2, fh/2, 0, 1)
+		top = 1.75 * fh
+	}
+	var legend []legendEntry
+	if f.shareLegend {
+		all := &Plot{}
+		for _, cell := range f.cells {
+			all.layers = append(all.layers, cell.plot.layers...)
+		}
+		legend = all.legendEntries(th)
+	}
+	annotationFont := typeface{th.AnnotationFont, math.Round(h / 50)}
+	if len(legend) != 0 {
+		bottom -= 2 * fontHeight(c, annotationFont)
+	}
+
+	// Size the grid.
+	var rows, cols int
+	for _, cell := range f.cells {
+		if cell.row < 0 || cell.col < 0 {
+			return fmt.Errorf("plot placed at row %d and column %d, outside the figure's grid", cell.row, cell.col)
+		}
+		rows = max(rows, cell.row+1)
+		cols = max(cols, cell.col+1)
+	}
+	ys := gridOffsets(f.rowHeights, rows, top, bottom)
+	xs := gridOffsets(f.colWidths, cols, 0, w)
+
+	// Find the bottom plot in each column, and the leftmost in each row,
+	// which keep their axis titles when axes are shared.
+	bottomRow := make([]int, cols)
+	leftCol := make([]int, rows)
+	for i := range leftCol {
+		leftCol[i] = cols
+	}
+	for _, cell := range f.cells {
+		bottomRow[cell.col] = max(bottomRow[cell.col], cell.row)
+		leftCol[cell.row] = min(leftCol[cell.row], cell.col)
+	}
+
+	// Work on copies of the plots, so that sharing doesn't affect them.
+	plots := make([]Plot, len(f.cells))
+	for i, cell := range f.cells {
+		plots[i] = *cell.plot
+		if f.shareLegend {
+			plots[i].opts.legend.shown, plots[i].opts.legend.hidden = false, true
+		}
+		if f.shareX && cell.row != bottomRow[cell.col] {
+			plots[i].opts.x.title = ""
+		}
+		if f.shareY && cell.col != leftCol[cell.row] {
+			plots[i].opts.y.title = ""
+		}
+	}
+	if f.shareX {
+		shareLimits(plots, func(p *Plot) *axis { return &p.opts.x })
+	}
+	if f.shareY {
+		shareLimits(plots, func(p *Plot) *axis { return &p.opts.y })
+	}
+
+	for i, cell := range f.cells {
+		x0, x1 := xs[cell.col], xs[cell.col+1]
+		y0, y1 := ys[cell.row], ys[cell.row+1]
+		c.push()
+		c.translate(x0, y0)
+		err := plots[i].draw(c, th, x1-x0, y1-y0)
+		c.pop()
+		if err != nil {
+			return err
+		}
+	}
+	if len(legend) != 0 {
+		thickness := math.Round(math.Sqrt(w * h / (1080 * 720)))
+		drawLegendRow(c, th, legend, annotationFont, thickness, 0, bottom+fontHeight(c, annotationFont)/2, w)
+	}
+	return nil
+}
+
+// gridOffsets divides [lo, hi] into n parts with the provided relative
+// sizes, and returns the n+1 boundaries between them.
+func gridOffsets(sizes []float64, n int, lo, hi float64) []float64 {
+	size := func(i int) float64 {
+		if i < len(sizes) {
+			return sizes[i]
+		}
+		return 1
+	}
+	var total float64
+	for i := range n {
+		total += size(i)
+	}
+	offsets := make([]float64, n+1)
+	offsets[0] = lo
+	for i := range n {
+		offsets[i+1] = offsets[i] + (hi-lo)*size(i)/total
+	}
+	return offsets
+}
+
+// shareLimits sets the limits of the selected axis of each plot to cover
+// the data of all of them.
+func shareLimits(plots []Plot, sel func(*Plot) *axis) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for i := range plots {
+		if len(plots[i].layers) == 0 {
+			continue
+		}
+		plots[i].computeRanges()
+		a := sel(&plots[i])
+		lo, hi = min(lo, a.min), max(hi, a.max)
+	}
+	if lo > hi {
+		return
+	}
+	for i := range plots {
+		a := sel(&plots[i])
+		a.userMin, a.userMax, a.userLimits = lo, hi, true
+	}
+}
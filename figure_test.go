@@ -0,0 +1,159 @@
+package ggg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGridOffsets(t *testing.T) {
+	type test struct {
+		name   string
+		sizes  []float64
+		n      int
+		lo, hi float64
+		want   []float64
+	}
+	for _, ts := range []test{
+		{
+			name: "Equal",
+			n:    4,
+			lo:   0, hi: 100,
+			want: []float64{0, 25, 50, 75, 100},
+		},
+		{
+			name:  "Relative",
+			sizes: []float64{1, 3},
+			n:     2,
+			lo:    20, hi: 100,
+			want: []float64{20, 40, 100},
+		},
+		{
+			// Parts without a size have a size of 1, and extra sizes are
+			// ignored.
+			name:  "Partial",
+			sizes: []float64{2},
+			n:     3,
+			lo:    0, hi: 100,
+			want: []float64{0, 50, 75, 100},
+		},
+		{
+			name:  "Extra",
+			sizes: []float64{1, 1, 8},
+			n:     2,
+			lo:    0, hi: 10,
+			want: []float64{0, 5, 10},
+		},
+		{
+			name: "Empty",
+			n:    0,
+			lo:   0, hi: 10,
+			want: []float64{0},
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			got := gridOffsets(ts.sizes, ts.n, ts.lo, ts.hi)
+			if !approxEqual(got, ts.want) {
+				t.Errorf("got %v, want %v", got, ts.want)
+			}
+		})
+	}
+}
+
+func TestShareLimits(t *testing.T) {
+	plots := []Plot{*pointsPlot(0, 5), *pointsPlot(-3, 2), *NewPlot()}
+	shareLimits(plots, func(p *Plot) *axis { return &p.opts.x })
+	shareLimits(plots, func(p *Plot) *axis { return &p.opts.y })
+	for i := range plots {
+		x, y := plots[i].opts.x, plots[i].opts.y
+		if !x.userLimits || x.userMin != -3 || x.userMax != 5 {
+			t.Errorf("plot %d has X limits [%v, %v], want [-3, 5]", i, x.userMin, x.userMax)
+		}
+		if !y.userLimits || y.userMin != 0 || y.userMax != 25 {
+			t.Errorf("plot %d has Y limits [%v, %v], want [0, 25]", i, y.userMin, y.userMax)
+		}
+	}
+}
+
+func TestFigureDraw(t *testing.T) {
+	d := Empty()
+	x, y, s := NewColumn[float64]("x"), NewColumn[float64]("y"), NewColumn[string]("s")
+	d.AddColumn(x)
+	d.AddColumn(y)
+	d.AddColumn(s)
+	for row := range d.Grow(4) {
+		x.Set(d, row, float64(row))
+		y.Set(d, row, float64(row))
+		s.Set(d, row, []string{"cod", "eel"}[row%2])
+	}
+	scatter := func() *Plot {
+		return ScatterPlot(d, x, y, s).Presentation(Legend(LegendTopRight), XAxis("x title"), YAxis("y title"))
+	}
+
+	type test struct {
+		name string
+		fig  *Figure
+
+		// legends is the number of legends drawn, and titles the number
+		// of each axis title.
+		legends, titles int
+		err             string
+	}
+	for _, ts := range []test{
+		{
+			name:    "Separate",
+			fig:     NewFigure().Place(0, 0, scatter()).Place(1, 1, scatter()),
+			legends: 2, titles: 2,
+		},
+		{
+			// Plots keep their own legends, even if they ask for one.
+			name:    "ShareLegend",
+			fig:     NewFigure(ShareLegend()).Place(0, 0, scatter()).Place(1, 1, scatter()),
+			legends: 1, titles: 2,
+		},
+		{
+			// Only the bottom plot in the column keeps its X axis title,
+			// and the leftmost in the row its Y axis title.
+			name:    "ShareAxes",
+			fig:     NewFigure(ShareX(), ShareY()).Place(0, 0, scatter()).Place(1, 0, scatter()).Place(1, 1, scatter()),
+			legends: 3, titles: 2,
+		},
+		{
+			name: "NegativeRow",
+			fig:  NewFigure().Place(-1, 0, scatter()),
+			err:  "row -1 and column 0",
+		},
+		{
+			name: "NegativeColumn",
+			fig:  NewFigure().Place(0, 0, scatter()).Place(0, -2, scatter()),
+			err:  "row 0 and column -2",
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			sc := newSVGCanvas(&buf, 600, 400)
+			err := ts.fig.draw(sc, testTheme(t), 600, 400)
+			if ts.err != "" {
+				if err == nil || !strings.Contains(err.Error(), ts.err) {
+					t.Errorf("got error %v, want one containing %q", err, ts.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := sc.finish(); err != nil {
+				t.Fatal(err)
+			}
+			out := buf.String()
+			if got := strings.Count(out, `<g class="legend">`); got != ts.legends {
+				t.Errorf("got %d legends, want %d", got, ts.legends)
+			}
+			for _, title := range []string{"x title", "y title"} {
+				if got := strings.Count(out, ">"+title+"<"); got != ts.titles {
+					t.Errorf("got %d of %q, want %d", got, title, ts.titles)
+				}
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package ggg
 import (
 	"cmp"
 	"regexp"
+	"unique"
 )
 
 type Filter interface {
@@ -75,6 +76,21 @@ func In[T comparable](c Column[T], values ...T) Filter {
 	})
 }
 
+// anyEqualTo returns a filter accepting rows where c, which must be in the
+// dataset, has the provided value, or one with the same key from valueKey.
+func anyEqualTo(c AnyColumn, value any) Filter {
+	return &filterAnyEqual{c.colKey(), valueKey(value)}
+}
+
+type filterAnyEqual struct {
+	key   unique.Handle[columnKey]
+	value any // The value's key, from valueKey.
+}
+
+func (f *filterAnyEqual) Accept(d *Dataset, row int) bool {
+	return valueKey(d.columns[d.colMap[f.key]].get(row)) == f.value
+}
+
 func Not(f Filter) Filter {
 	return &filterNot{f}
 }
@@ -0,0 +1,219 @@
+package ggg
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Formatter produces the label for a tick at some position along an axis.
+type Formatter func(float64) string
+
+// FormatFixed formats tick labels with prec digits after the decimal point.
+func FormatFixed(prec int) Formatter {
+	return func(x float64) string {
+		return strconv.FormatFloat(x, 'f', prec, 64)
+	}
+}
+
+// FormatExponent formats tick labels in scientific notation with prec digits
+// after the decimal point, like "1.05e+09".
+func FormatExponent(prec int) Formatter {
+	return func(x float64) string {
+		return strconv.FormatFloat(x, 'e', prec, 64)
+	}
+}
+
+// FormatPercent formats tick labels as percentages with prec digits after
+// the decimal point. A value of 1 is 100%.
+func FormatPercent(prec int) Formatter {
+	return func(x float64) string {
+		return strconv.FormatFloat(x*100, 'f', prec, 64) + "%"
+	}
+}
+
+var siPrefixes = []string{"p", "n", "µ", "m", "", "k", "M", "G", "T", "P", "E"}
+
+// siUnity is the index of the empty prefix in siPrefixes.
+const siUnity = 4
+
+// FormatSI formats tick labels with at most prec significant digits and an
+// SI prefix, like "1.5k", "20M", or "250µ".
+func FormatSI(prec int) Formatter {
+	return func(x float64) string {
+		return formatPrefixed(x, prec, 1000, siPrefixes, siUnity, "")
+	}
+}
+
+var binaryPrefixes = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+
+// FormatBytes formats tick labels representing a number of bytes with at
+// most prec significant digits and a binary prefix, like "1 GiB".
+func FormatBytes(prec int) Formatter {
+	return func(x float64) string {
+		return formatPrefixed(x, prec, 1024, binaryPrefixes, 0, " ")
+	}
+}
+
+// formatPrefixed formats x with at most prec significant digits, scaled
+// down by the largest power of base such that the magnitude of the result
+// is at least one. The power is indicated by one of the prefixes, where
+// unity is the index of the prefix for a power of zero.
+func formatPrefixed(x float64, prec int, base float64, prefixes []string, unity int, sep string) string {
+	if x == 0 || math.IsInf(x, 0) || math.IsNaN(x) {
+		return formatSignificant(x, prec) + sep + prefixes[unity]
+	}
+	i := int(math.Floor(math.Log(math.Abs(x)) / math.Log(base)))
+	i = min(max(i, -unity), len(prefixes)-1-unity)
+	m := x / math.Pow(base, float64(i))
+	s := formatSignificant(m, prec)
+
+	// Rounding may have pushed us up to the next prefix.
+	if r, _ := strconv.ParseFloat(s, 64); math.Abs(r) >= base && i+unity+1 < len(prefixes) {
+		i++
+		m = x / math.Pow(base, float64(i))
+		s = formatSignificant(m, prec)
+	}
+	return s + sep + prefixes[i+unity]
+}
+
+// FormatDuration formats tick labels representing a time.Duration with at
+// most prec significant digits, using the largest unit that keeps the
+// magnitude at least one, like "250ms" or "1.5s".
+func FormatDuration(prec int) Formatter {
+	return func(x float64) string {
+		if x == 0 || math.IsInf(x, 0) || math.IsNaN(x) {
+			return formatSignificant(x, prec) + "s"
+		}
+		i := len(durationUnits) - 1
+		for j, u := range durationUnits {
+			if math.Abs(x) >= float64(u.d) {
+				i = j
+				break
+			}
+		}
+		s := formatSignificant(x/float64(durationUnits[i].d), prec)
+
+		// Rounding may have pushed us up to the next unit. Units aren't all
+		// a power of ten apart, so convert the rounded value, rather than
+		// rounding again: 59.6s to two digits is 60s, which is 1m, not 0.99m.
+		if i > 0 {
+			r, _ := strconv.ParseFloat(s, 64)
+			if ratio := float64(durationUnits[i-1].d / durationUnits[i].d); math.Abs(r) >= ratio {
+				i--
+				s = formatSignificant(r/ratio, prec)
+			}
+		}
+		return s + durationUnits[i].suffix
+	}
+}
+
+var durationUnits = []struct {
+	d      time.Duration
+	suffix string
+}{
+	{time.Hour, "h"},
+	{time.Minute, "m"},
+	{time.Second, "s"},
+	{time.Millisecond, "ms"},
+	{time.Microsecond, "µs"},
+	{time.Nanosecond, "ns"},
+}
+
+// FormatTime formats tick labels representing a time.Time with the provided
+// layout, as understood by time.Time.Format, in the local time zone.
+func FormatTime(layout string) Formatter {
+	return func(x float64) string {
+		return floatToTime(x).Format(layout)
+	}
+}
+
+// FormatPower formats tick labels that are integral powers of base as the
+// base with a superscript exponent, like "10³". Other labels are formatted
+// with three significant digits. It's intended for use with LogScale.
+func FormatPower(base int) Formatter {
+	log := logFunc(base)
+	return func(x float64) string {
+		if x > 0 {
+			e := math.Round(log(x))
+			if math.Abs(log(x)-e) < 1e-9 {
+				return strconv.Itoa(base) + toSuperscript(strconv.Itoa(int(e)))
+			}
+		}
+		return formatSignificant(x, 3)
+	}
+}
+
+// formatSignificant formats x with at most prec significant digits and
+// without an exponent.
+func formatSignificant(x float64, prec int) string {
+	r, err := strconv.ParseFloat(strconv.FormatFloat(x, 'g', prec, 64), 64)
+	if err != nil {
+		return strconv.FormatFloat(x, 'g', prec, 64)
+	}
+	return strconv.FormatFloat(r, 'f', -1, 64)
+}
+
+const (
+	superscriptDigits = "⁰¹²³⁴⁵⁶⁷⁸⁹"
+	superscriptMinus  = '⁻'
+)
+
+// toSuperscript converts the digits and minus signs in s to their
+// superscript forms.
+func toSuperscript(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune([]rune(superscriptDigits)[r-'0'])
+		case r == '-':
+			b.WriteRune(superscriptMinus)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// textRun is a piece of a label that's either drawn normally or as a
+// superscript.
+type textRun struct {
+	text        string
+	superscript bool
+}
+
+// superscriptRuns splits s into runs of normal and superscript text. The text
+// of superscript runs is converted back to normal digits and minus signs so
+// that it may be drawn with a smaller, raised font, since few fonts contain
+// glyphs for every superscript character.
+func superscriptRuns(s string) []textRun {
+	var runs []textRun
+	var b strings.Builder
+	sup := false
+	for len(s) > 0 {
+		r, n := utf8.DecodeRuneInString(s)
+		s = s[n:]
+		var norm rune
+		isSup := true
+		if i := strings.IndexRune(superscriptDigits, r); i >= 0 {
+			norm = '0' + rune(utf8.RuneCountInString(superscriptDigits[:i]))
+		} else if r == superscriptMinus {
+			norm = '-'
+		} else {
+			norm, isSup = r, false
+		}
+		if isSup != sup && b.Len() > 0 {
+			runs = append(runs, textRun{b.String(), sup})
+			b.Reset()
+		}
+		sup = isSup
+		b.WriteRune(norm)
+	}
+	if b.Len() > 0 {
+		runs = append(runs, textRun{b.String(), sup})
+	}
+	return runs
+}
@@ -0,0 +1,61 @@
+package ggg
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFormatDuration(t *testing.T) {
+	type test struct {
+		name string
+		prec int
+		in   float64
+		want string
+	}
+	for _, ts := range []test{
+		{name: "Zero", prec: 3, in: 0, want: "0s"},
+		{name: "Nanoseconds", prec: 3, in: 250, want: "250ns"},
+		{name: "Microseconds", prec: 3, in: 1500, want: "1.5µs"},
+		{name: "Milliseconds", prec: 3, in: float64(250 * time.Millisecond), want: "250ms"},
+		{name: "Seconds", prec: 3, in: float64(1500 * time.Millisecond), want: "1.5s"},
+		{name: "Minutes", prec: 3, in: float64(90 * time.Second), want: "1.5m"},
+		{name: "Hours", prec: 3, in: float64(36 * time.Hour), want: "36h"},
+		{name: "Negative", prec: 3, in: float64(-2 * time.Second), want: "-2s"},
+		{name: "CarryNanoseconds", prec: 3, in: 999.6, want: "1µs"},
+		{name: "CarryMilliseconds", prec: 3, in: 999.6e6, want: "1s"},
+		{name: "CarrySeconds", prec: 2, in: 59.6e9, want: "1m"},
+		{name: "CarryMinutes", prec: 2, in: float64(59*time.Minute + 50*time.Second), want: "1h"},
+		{name: "NoCarry", prec: 3, in: 59.6e9, want: "59.6s"},
+		{name: "Sub", prec: 3, in: 0.5, want: "0.5ns"},
+		{name: "Inf", prec: 3, in: math.Inf(1), want: "+Infs"},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			if got := FormatDuration(ts.prec)(ts.in); got != ts.want {
+				t.Errorf("FormatDuration(%d)(%v) = %q, want %q", ts.prec, ts.in, got, ts.want)
+			}
+		})
+	}
+}
+
+func TestFormatPrefixed(t *testing.T) {
+	type test struct {
+		name string
+		f    Formatter
+		in   float64
+		want string
+	}
+	for _, ts := range []test{
+		{name: "SI", f: FormatSI(3), in: 1500, want: "1.5k"},
+		{name: "SIMicro", f: FormatSI(3), in: 250e-6, want: "250µ"},
+		{name: "SICarry", f: FormatSI(3), in: 999.6e3, want: "1M"},
+		{name: "Bytes", f: FormatBytes(3), in: 1 << 30, want: "1 GiB"},
+		{name: "BytesCarry", f: FormatBytes(4), in: 1023.96 * 1024, want: "1 MiB"},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			if got := ts.f(ts.in); got != ts.want {
+				t.Errorf("got %q for %v, want %q", got, ts.in, ts.want)
+			}
+		})
+	}
+}
@@ -3,8 +3,7 @@ package ggg
 import (
 	"fmt"
 	"image/color"
-
-	"github.com/fogleman/gg"
+	"math"
 )
 
 type Geom struct {
@@ -12,7 +11,15 @@ type Geom struct {
 	dims     int
 	color    Mapping[color.Color]
 	size     Mapping[float64]
+	height   Mapping[float64]
+	label    Mapping[string]
+	repel    bool
+	step     float64
 	grouping func(d *Dataset, row int) any
+
+	// xEnd and yEnd, if not nil, position the other end of each row's
+	// segment or span.
+	xEnd, yEnd func(d *Dataset, row int) float64
 }
 
 type kindGeom int
@@ -21,6 +28,15 @@ const (
 	kindBadGeom kindGeom = iota
 	kindPoint
 	kindLine
+	kindBar
+	kindViolin
+	kindBox
+	kindTile
+	kindText
+	kindRibbon
+	kindStep
+	kindSegment
+	kindSpan
 )
 
 func (g *Geom) Dimensions() int {
@@ -51,21 +67,269 @@ func Line(color Mapping[color.Color], size Mapping[float64]) *Geom {
 	}
 }
 
+// Step connects the points of each series with horizontal and vertical
+// lines, holding each Y value until the next X value, where it steps to the
+// next Y value. It suits series that change at discrete points, such as
+// cumulative distributions.
+func Step(color Mapping[color.Color], size Mapping[float64]) *Geom {
+	return &Geom{
+		kind:  kindStep,
+		dims:  1,
+		color: color,
+		size:  size,
+		step:  1,
+		grouping: func(d *Dataset, row int) any {
+			return any2{color.selector(d, row), size.selector(d, row)}
+		},
+	}
+}
+
+// StepPre is like Step, but steps to each Y value at the previous X value,
+// holding it until its own X value.
+func StepPre(color Mapping[color.Color], size Mapping[float64]) *Geom {
+	g := Step(color, size)
+	g.step = 0
+	return g
+}
+
+// StepMid is like Step, but steps to each Y value halfway between the
+// previous X value and its own.
+func StepMid(color Mapping[color.Color], size Mapping[float64]) *Geom {
+	g := Step(color, size)
+	g.step = 0.5
+	return g
+}
+
+// Segment draws a line from each X and Y value to the values of xend and
+// yend in the same row.
+func Segment[X, Y Value](xend Column[X], yend Column[Y], color Mapping[color.Color], size Mapping[float64]) *Geom {
+	return &Geom{
+		kind:  kindSegment,
+		dims:  1,
+		color: color,
+		size:  size,
+		grouping: func(d *Dataset, row int) any {
+			return any2{color.selector(d, row), size.selector(d, row)}
+		},
+		xEnd: func(d *Dataset, row int) float64 {
+			return toFloat(xend.Get(d, row))
+		},
+		yEnd: func(d *Dataset, row int) float64 {
+			return toFloat(yend.Get(d, row))
+		},
+	}
+}
+
+// Span draws a horizontal bar centered on each Y value, from its X value to
+// the value of end in the same row, for example to draw the intervals of a
+// timeline. Categorize places each lane of a timeline along the Y axis. The
+// height of the bars is in units of the Y axis.
+//
+// Spans that touch on the plot are drawn together, so timelines with very
+// many intervals draw quickly.
+func Span[X Value](end Column[X], color Mapping[color.Color], height Mapping[float64]) *Geom {
+	return &Geom{
+		kind:   kindSpan,
+		dims:   1,
+		color:  color,
+		size:   Constant(0.0),
+		height: height,
+		grouping: func(d *Dataset, row int) any {
+			return any2{color.selector(d, row), height.selector(d, row)}
+		},
+		xEnd: func(d *Dataset, row int) float64 {
+			return toFloat(end.Get(d, row))
+		},
+	}
+}
+
+// Bar draws a bar from zero up to each Y value, centered on its X value.
+// The width of the bars is in units of the X axis.
+func Bar(color Mapping[color.Color], width Mapping[float64]) *Geom {
+	return &Geom{
+		kind:  kindBar,
+		dims:  1,
+		color: color,
+		size:  width,
+		grouping: func(d *Dataset, row int) any {
+			return any2{color.selector(d, row), width.selector(d, row)}
+		},
+	}
+}
+
+// Violin draws the estimated distribution of values at each position along
+// the X axis, mirrored on either side of the position. The width of the
+// violins is in units of the X axis. It can only be used with Density.
+func Violin(color Mapping[color.Color], width Mapping[float64]) *Geom {
+	return &Geom{
+		kind:  kindViolin,
+		dims:  1,
+		color: color,
+		size:  width,
+		grouping: func(d *Dataset, row int) any {
+			return any2{color.selector(d, row), width.selector(d, row)}
+		},
+	}
+}
+
+// Box draws a box and whiskers for each X value, from the 5 dimensions
+// computed by the Quantiles statistic, along with points for the outliers
+// beyond the whiskers. The width of the boxes is in units of the X axis.
+func Box(color Mapping[color.Color], width Mapping[float64]) *Geom {
+	return &Geom{
+		kind:  kindBox,
+		dims:  5,
+		color: color,
+		size:  width,
+		grouping: func(d *Dataset, row int) any {
+			return any2{color.selector(d, row), width.selector(d, row)}
+		},
+	}
+}
+
+// Tile fills a rectangle centered on each X and Y value, for example to
+// draw a heatmap with a continuous color mapping like ScaleColor. The width
+// and height of the tiles are in units of the X and Y axes.
+func Tile(fill Mapping[color.Color], width, height Mapping[float64]) *Geom {
+	return &Geom{
+		kind:   kindTile,
+		dims:   1,
+		color:  fill,
+		size:   width,
+		height: height,
+		grouping: func(d *Dataset, row int) any {
+			key := any2{width.selector(d, row), height.selector(d, row)}
+			if fill.bar != nil {
+				// Tiles of all colors make up one series.
+				return key
+			}
+			return any2{fill.selector(d, row), key}
+		},
+	}
+}
+
+// Text draws a label centered on each X and Y value. The size of the text
+// is its font size in pixels at the default plot size.
+func Text(label Mapping[string], color Mapping[color.Color], size Mapping[float64]) *Geom {
+	return &Geom{
+		kind:  kindText,
+		dims:  1,
+		color: color,
+		size:  size,
+		label: label,
+		grouping: func(d *Dataset, row int) any {
+			return any2{color.selector(d, row), size.selector(d, row)}
+		},
+	}
+}
+
+// TextRepel is like Text, but moves labels away from their X and Y values
+// so they don't overlap each other, drawing a line back to each moved
+// label's position.
+func TextRepel(label Mapping[string], color Mapping[color.Color], size Mapping[float64]) *Geom {
+	g := Text(label, color, size)
+	g.repel = true
+	return g
+}
+
+// Ribbon shades the band between the 2 dimensions computed by a statistic
+// like Confidence, along the X axis. The band is drawn translucently, so
+// that it can sit behind a line through the same points.
+func Ribbon(color Mapping[color.Color]) *Geom {
+	return &Geom{
+		kind:  kindRibbon,
+		dims:  2,
+		color: color,
+		size:  Constant(0.0),
+		grouping: func(d *Dataset, row int) any {
+			return color.selector(d, row)
+		},
+	}
+}
+
+// maxSize returns the largest size the geom maps rows of d to.
+func (g *Geom) maxSize(d *Dataset) float64 {
+	return maxOf(d, g.size)
+}
+
+// endRange returns the range of the values end maps rows of d to.
+func endRange(d *Dataset, end func(*Dataset, int) float64) (lo, hi float64) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+	for row := range d.Rows() {
+		v := end(d, row)
+		lo, hi = min(lo, v), max(hi, v)
+	}
+	return lo, hi
+}
+
+// maxOf returns the largest value m maps rows of d to.
+func maxOf(d *Dataset, m Mapping[float64]) float64 {
+	var size float64
+	for row := range d.Rows() {
+		size = max(size, m.scale(d, row, nil))
+	}
+	return size
+}
+
+// legend returns legend entries for each distinct color the geom maps rows
+// of d to.
+func (g *Geom) legend(d *Dataset, theme *Theme) []legendEntry {
+	if g.color.bar != nil {
+		return []legendEntry{{label: g.color.bar.title, bar: g.color.bar}}
+	}
+	if g.color.selector == nil {
+		return nil
+	}
+	var entries []legendEntry
+	seen := make(map[any]bool)
+	for row := range d.Rows() {
+		key := g.color.selector(d, row)
+		if key == nil || seen[key] {
+			continue
+		}
+		seen[key] = true
+		entries = append(entries, legendEntry{
+			label: fmt.Sprint(key),
+			color: g.color.scale(d, row, theme),
+			line:  g.kind == kindLine || g.kind == kindStep || g.kind == kindSegment,
+			point: g.kind == kindPoint,
+			fill:  g.kind == kindBar || g.kind == kindViolin || g.kind == kindBox || g.kind == kindTile || g.kind == kindRibbon || g.kind == kindSpan,
+		})
+	}
+	return entries
+}
+
+// seriesLabel returns the legend label for the series containing row, or
+// the empty string if the series has no legend entry.
+func (g *Geom) seriesLabel(d *Dataset, row int) string {
+	if g.color.bar != nil {
+		return ""
+	}
+	if key := g.color.selector(d, row); key != nil {
+		return fmt.Sprint(key)
+	}
+	return ""
+}
+
 type any2 struct {
 	a, b any
 }
 
-func (g *Geom) drawer(th *Theme, c *gg.Context, xScale, yScale scaleFunc, scaleFactor float64) func(*Dataset, int, float64, []float64) {
+// drawer returns a function that draws one data point of a series, and a
+// function to call once all of its points are drawn, if not nil. Labels
+// already placed by the layer are tracked by labels, so they may avoid each
+// other.
+func (g *Geom) drawer(th *Theme, c canvas, xScale, yScale scaleFunc, scaleFactor float64, labels *labelPlacer) (draw func(*Dataset, int, float64, []float64), flush func()) {
 	switch g.kind {
 	case kindPoint:
 		return func(d *Dataset, row int, x float64, y []float64) {
 			if g.dims != len(y) {
 				panic(fmt.Sprintf("%d-dimensional data applied to %d-dimensional geom", len(y), g.dims))
 			}
-			c.SetColor(g.color.scale(d, row, th))
-			c.DrawCircle(xScale(x), yScale(y[0]), scaleFactor*g.size.scale(d, row, th))
-			c.Fill()
-		}
+			c.setColor(g.color.scale(d, row, th))
+			c.circle(xScale(x), yScale(y[0]), scaleFactor*g.size.scale(d, row, th))
+			c.fill()
+		}, nil
 	case kindLine:
 		var prev struct {
 			x, y  float64
@@ -76,16 +340,208 @@ func (g *Geom) drawer(th *Theme, c *gg.Context, xScale, yScale scaleFunc, scaleF
 				panic(fmt.Sprintf("%d-dimensional data applied to %d-dimensional geom", len(y), g.dims))
 			}
 			if prev.valid {
-				c.SetColor(g.color.scale(d, row, th))
-				c.MoveTo(xScale(prev.x), yScale(prev.y))
-				c.LineTo(xScale(x), yScale(y[0]))
-				c.SetLineWidth(scaleFactor * g.size.scale(d, row, th))
-				c.Stroke()
+				c.setColor(g.color.scale(d, row, th))
+				c.moveTo(xScale(prev.x), yScale(prev.y))
+				c.lineTo(xScale(x), yScale(y[0]))
+				c.setLineWidth(scaleFactor * g.size.scale(d, row, th))
+				c.stroke()
+			}
+			prev.x = x
+			prev.y = y[0]
+			prev.valid = true
+		}, nil
+	case kindStep:
+		var prev struct {
+			x, y  float64
+			valid bool
+		}
+		return func(d *Dataset, row int, x float64, y []float64) {
+			if g.dims != len(y) {
+				panic(fmt.Sprintf("%d-dimensional data applied to %d-dimensional geom", len(y), g.dims))
+			}
+			if prev.valid {
+				// Step at a fraction of the way between the points on
+				// the canvas, so mid steps look right on any axis.
+				x0, x1 := xScale(prev.x), xScale(x)
+				xs := x0 + g.step*(x1-x0)
+				c.setColor(g.color.scale(d, row, th))
+				c.moveTo(x0, yScale(prev.y))
+				c.lineTo(xs, yScale(prev.y))
+				c.lineTo(xs, yScale(y[0]))
+				c.lineTo(x1, yScale(y[0]))
+				c.setLineWidth(scaleFactor * g.size.scale(d, row, th))
+				c.stroke()
 			}
 			prev.x = x
 			prev.y = y[0]
 			prev.valid = true
+		}, nil
+	case kindBar:
+		return func(d *Dataset, row int, x float64, y []float64) {
+			if g.dims != len(y) {
+				panic(fmt.Sprintf("%d-dimensional data applied to %d-dimensional geom", len(y), g.dims))
+			}
+			w := g.size.scale(d, row, th)
+			c.setColor(g.color.scale(d, row, th))
+			fillBar(c, xScale, yScale, x-w/2, x+w/2, y[0])
+		}, nil
+	case kindTile:
+		return func(d *Dataset, row int, x float64, y []float64) {
+			if g.dims != len(y) {
+				panic(fmt.Sprintf("%d-dimensional data applied to %d-dimensional geom", len(y), g.dims))
+			}
+			w, h := g.size.scale(d, row, th), g.height.scale(d, row, th)
+			x0, x1 := xScale(x-w/2), xScale(x+w/2)
+			y0, y1 := yScale(y[0]-h/2), yScale(y[0]+h/2)
+			c.setColor(g.color.scale(d, row, th))
+			c.rect(min(x0, x1), min(y0, y1), math.Abs(x1-x0), math.Abs(y1-y0))
+			c.fill()
+		}, nil
+	case kindText:
+		return func(d *Dataset, row int, x float64, y []float64) {
+			if g.dims != len(y) {
+				panic(fmt.Sprintf("%d-dimensional data applied to %d-dimensional geom", len(y), g.dims))
+			}
+			tf := typeface{th.AnnotationFont, scaleFactor * g.size.scale(d, row, th)}
+			c.setColor(g.color.scale(d, row, th))
+			labels.draw(c, tf, g.label.scale(d, row, th), xScale(x), yScale(y[0]), g.repel, scaleFactor)
+		}, nil
+	case kindRibbon:
+		var prev struct {
+			x, lo, hi float64
+			valid     bool
 		}
+		return func(d *Dataset, row int, x float64, y []float64) {
+			if g.dims != len(y) {
+				panic(fmt.Sprintf("%d-dimensional data applied to %d-dimensional geom", len(y), g.dims))
+			}
+			if math.IsInf(y[0], 0) || math.IsInf(y[1], 0) {
+				// Too few values to bound, so leave a gap.
+				prev.valid = false
+				return
+			}
+			if prev.valid {
+				c.setColor(faint(g.color.scale(d, row, th)))
+				c.moveTo(xScale(prev.x), yScale(prev.lo))
+				c.lineTo(xScale(x), yScale(y[0]))
+				c.lineTo(xScale(x), yScale(y[1]))
+				c.lineTo(xScale(prev.x), yScale(prev.hi))
+				c.closePath()
+				c.fill()
+			}
+			prev.x, prev.lo, prev.hi = x, y[0], y[1]
+			prev.valid = true
+		}, nil
+	case kindSegment:
+		return func(d *Dataset, row int, x float64, y []float64) {
+			if g.dims != len(y) {
+				panic(fmt.Sprintf("%d-dimensional data applied to %d-dimensional geom", len(y), g.dims))
+			}
+			c.setColor(g.color.scale(d, row, th))
+			c.moveTo(xScale(x), yScale(y[0]))
+			c.lineTo(xScale(g.xEnd(d, row)), yScale(g.yEnd(d, row)))
+			c.setLineWidth(scaleFactor * g.size.scale(d, row, th))
+			c.stroke()
+		}, nil
+	case kindSpan:
+		return g.spanDrawer(th, c, xScale, yScale)
+	case kindBox:
+		return func(d *Dataset, row int, x float64, y []float64) {
+			if g.dims != len(y) {
+				panic(fmt.Sprintf("%d-dimensional data applied to %d-dimensional geom", len(y), g.dims))
+			}
+			col := g.color.scale(d, row, th)
+			w := g.size.scale(d, row, th)
+			x0, x1 := xScale(x-w/2), xScale(x+w/2)
+			xm := xScale(x)
+			lo, q1, med, q3, hi := yScale(y[0]), yScale(y[1]), yScale(y[2]), yScale(y[3]), yScale(y[4])
+
+			// Fill the box faintly, so the median stands out.
+			c.setColor(faint(col))
+			c.rect(min(x0, x1), min(q1, q3), math.Abs(x1-x0), math.Abs(q3-q1))
+			c.fill()
+
+			c.setColor(col)
+			c.setLineWidth(1.5 * scaleFactor)
+			c.rect(min(x0, x1), min(q1, q3), math.Abs(x1-x0), math.Abs(q3-q1))
+			c.moveTo(xm, q1)
+			c.lineTo(xm, lo)
+			c.moveTo(xm, q3)
+			c.lineTo(xm, hi)
+			c.moveTo((x0+xm)/2, lo)
+			c.lineTo((x1+xm)/2, lo)
+			c.moveTo((x0+xm)/2, hi)
+			c.lineTo((x1+xm)/2, hi)
+			c.stroke()
+			c.moveTo(x0, med)
+			c.lineTo(x1, med)
+			c.setLineWidth(3 * scaleFactor)
+			c.stroke()
+		}, nil
 	}
 	panic("attempted to draw invalid Geom")
 }
+
+// spanDrawer returns a drawer for the Span geom. Rather than drawing each
+// span right away, it extends the last span in the same lane to cover it if
+// they're the same color and touch on the canvas, which is the case for
+// most spans in dense timelines. The series' points must be in X order.
+func (g *Geom) spanDrawer(th *Theme, c canvas, xScale, yScale scaleFunc) (draw func(*Dataset, int, float64, []float64), flush func()) {
+	type pending struct {
+		x0, x1, y0, y1 float64
+		color          color.Color
+	}
+	lanes := make(map[float64]*pending)
+	var order []float64
+	fill := func(p *pending) {
+		c.setColor(p.color)
+		c.rect(p.x0, p.y0, p.x1-p.x0, p.y1-p.y0)
+		c.fill()
+	}
+	draw = func(d *Dataset, row int, x float64, y []float64) {
+		if g.dims != len(y) {
+			panic(fmt.Sprintf("%d-dimensional data applied to %d-dimensional geom", len(y), g.dims))
+		}
+		h := g.height.scale(d, row, th)
+		x0, x1 := xScale(x), xScale(g.xEnd(d, row))
+		y0, y1 := yScale(y[0]-h/2), yScale(y[0]+h/2)
+		next := &pending{min(x0, x1), max(x0, x1), min(y0, y1), max(y0, y1), g.color.scale(d, row, th)}
+		p, ok := lanes[y[0]]
+		if !ok {
+			order = append(order, y[0])
+		} else if p.color == next.color && p.y0 == next.y0 && p.y1 == next.y1 && next.x0 <= p.x1+0.5 {
+			p.x1 = max(p.x1, next.x1)
+			return
+		} else {
+			fill(p)
+		}
+		lanes[y[0]] = next
+	}
+	flush = func() {
+		for _, lane := range order {
+			fill(lanes[lane])
+		}
+	}
+	return draw, flush
+}
+
+// faint returns a translucent version of c, for filling areas behind
+// lines.
+func faint(c color.Color) color.Color {
+	f := color.NRGBAModel.Convert(c).(color.NRGBA)
+	f.A /= 3
+	return f
+}
+
+// fillBar fills the bar spanning x0 to x1 on the X axis, from the baseline
+// up to y. The baseline is zero, or one if the Y axis can't show zero.
+func fillBar(c canvas, xScale, yScale scaleFunc, x0, x1, y float64) {
+	base := yScale(0)
+	if math.IsInf(base, 0) || math.IsNaN(base) {
+		base = yScale(1)
+	}
+	sx0, sx1 := xScale(x0), xScale(x1)
+	sy := yScale(y)
+	c.rect(min(sx0, sx1), min(base, sy), math.Abs(sx1-sx0), math.Abs(sy-base))
+	c.fill()
+}
@@ -0,0 +1,394 @@
+package ggg
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// Histogram is a layer that counts how many values of a column fall into
+// each of a series of bins along the X axis.
+//
+// The histogram is drawn according to the kind of its Geom. Bar draws a bar
+// for each bin, filling the width of the bin. Line or Step draws a step
+// outline around the bins, and Point draws a point at the center of each
+// bin. Rows are split into series by the Geom's color and size, and all
+// series share the same bins.
+type Histogram[T Scalar] struct {
+	Data *Dataset
+	X    Column[T]
+	Bins Binning
+	Geom *Geom
+
+	// Density normalizes the height of each bin so that the area of the
+	// histogram is 1.
+	Density bool
+
+	// filter, if not nil, restricts the rows that are counted.
+	filter Filter
+
+	// prepared is set if edges and series hold the bins, as computed by
+	// prepare.
+	prepared bool
+	edges    []float64
+	series   []*histSeries
+}
+
+// histSeries is one series of a histogram.
+type histSeries struct {
+	row    int // A representative row, for the series' color and size.
+	counts []int
+	n      int // Total number of values in the bins.
+}
+
+// bins splits the histogram's rows into series and counts the values in
+// each bin. Bin edges are computed over all rows, so they stay the same
+// however the histogram is filtered.
+func (h *Histogram[T]) bins() (edges []float64, ss []*histSeries) {
+	if h.prepared {
+		return h.edges, h.series
+	}
+	if h.Geom == nil || h.Data == nil || !h.X.Valid() || h.Bins.err != nil {
+		return nil, nil
+	}
+	var values []float64
+	for v := range h.X.All(h.Data) {
+		if f := toFloat(v); !math.IsNaN(f) && !math.IsInf(f, 0) {
+			values = append(values, f)
+		}
+	}
+	slices.Sort(values)
+	edges = h.Bins.edges(values)
+	if len(edges) < 2 {
+		return nil, nil
+	}
+
+	smap := make(map[any]*histSeries)
+	for row := range filteredRows(h.Data, h.filter) {
+		i := binIndex(edges, toFloat(h.X.Get(h.Data, row)))
+		if i < 0 {
+			continue
+		}
+		key := h.Geom.grouping(h.Data, row)
+		s, ok := smap[key]
+		if !ok {
+			s = &histSeries{row: row, counts: make([]int, len(edges)-1)}
+			smap[key] = s
+			ss = append(ss, s)
+		}
+		s.counts[i]++
+		s.n++
+	}
+	return edges, ss
+}
+
+func (h *Histogram[T]) prepare() AnyLayer {
+	c := *h
+	c.edges, c.series = h.bins()
+	c.prepared = true
+	return &c
+}
+
+// binIndex returns the index of the bin containing v, or -1 if v is outside
+// all the bins. Bins include their lower edge, and the last bin also
+// includes its upper edge.
+func binIndex(edges []float64, v float64) int {
+	if !(v >= edges[0] && v <= edges[len(edges)-1]) {
+		return -1
+	}
+	i, _ := slices.BinarySearch(edges, v)
+	if i < len(edges) && edges[i] == v {
+		i++
+	}
+	return min(i, len(edges)-1) - 1
+}
+
+// height returns the height of bin i of s.
+func (h *Histogram[T]) height(edges []float64, s *histSeries, i int) float64 {
+	if !h.Density {
+		return float64(s.counts[i])
+	}
+	return float64(s.counts[i]) / (float64(s.n) * (edges[i+1] - edges[i]))
+}
+
+func (h *Histogram[T]) xRange() (lo, hi float64) {
+	edges, _ := h.bins()
+	if len(edges) == 0 {
+		return 0, 0
+	}
+	return edges[0], edges[len(edges)-1]
+}
+
+func (h *Histogram[T]) yRange() (lo, hi float64) {
+	edges, ss := h.bins()
+	for _, s := range ss {
+		for i := range s.counts {
+			hi = max(hi, h.height(edges, s, i))
+		}
+	}
+	return 0, hi
+}
+
+func (h *Histogram[T]) xKind() valueKind {
+	return kindOf[T]()
+}
+
+func (h *Histogram[T]) yKind() valueKind {
+	return valueNumber
+}
+
+func (h *Histogram[T]) legend(theme *Theme) []legendEntry {
+	if h.Geom == nil || h.Data == nil {
+		return nil
+	}
+	return h.Geom.legend(h.Data, theme)
+}
+
+func (h *Histogram[T]) dataset() *Dataset {
+	return h.Data
+}
+
+// where returns a copy of the histogram that only counts rows accepted by f.
+func (h *Histogram[T]) where(f Filter) AnyLayer {
+	c := *h
+	if c.filter != nil {
+		f = And(c.filter, f)
+	}
+	c.filter = f
+	c.prepared, c.edges, c.series = false, nil, nil
+	return &c
+}
+
+func (h *Histogram[T]) render(c canvas, theme *Theme, xScale, yScale scaleFunc, scaleFactor float64) error {
+	if h.Geom == nil || h.Geom.kind == kindBadGeom {
+		return fmt.Errorf("no initialized Geom for histogram")
+	}
+	if h.Data == nil {
+		return fmt.Errorf("no intended dataset specified for histogram")
+	}
+	if !h.X.Valid() {
+		return fmt.Errorf("no initialized X column for histogram")
+	}
+	if h.Bins.err != nil {
+		return h.Bins.err
+	}
+
+	edges, ss := h.bins()
+	rec, _ := c.(pointRecorder)
+	yName := "count"
+	if h.Density {
+		yName = "density"
+	}
+	for _, s := range ss {
+		label := h.Geom.seriesLabel(h.Data, s.row)
+		beginGroup(c, "series", label)
+		c.setColor(h.Geom.color.scale(h.Data, s.row, theme))
+		size := scaleFactor * h.Geom.size.scale(h.Data, s.row, theme)
+		switch h.Geom.kind {
+		case kindBar:
+			for i := range s.counts {
+				if s.counts[i] != 0 {
+					fillBar(c, xScale, yScale, edges[i], edges[i+1], h.height(edges, s, i))
+				}
+			}
+		case kindLine, kindStep:
+			base := yScale(0)
+			if math.IsInf(base, 0) || math.IsNaN(base) {
+				base = yScale(1)
+			}
+			c.moveTo(xScale(edges[0]), base)
+			for i := range s.counts {
+				y := yScale(h.height(edges, s, i))
+				c.lineTo(xScale(edges[i]), y)
+				c.lineTo(xScale(edges[i+1]), y)
+			}
+			c.lineTo(xScale(edges[len(edges)-1]), base)
+			c.setLineWidth(size)
+			c.stroke()
+		case kindPoint:
+			for i := range s.counts {
+				c.circle(xScale((edges[i]+edges[i+1])/2), yScale(h.height(edges, s, i)), size)
+				c.fill()
+			}
+		default:
+			return fmt.Errorf("histogram can't be drawn with this Geom")
+		}
+		if rec != nil {
+			for i := range s.counts {
+				y := h.height(edges, s, i)
+				rec.recordPoint(label, xScale((edges[i]+edges[i+1])/2), yScale(y), []field{
+					{h.X.Name(), h.binLabel(edges, i)},
+					{yName, strconv.FormatFloat(y, 'g', 6, 64)},
+					{"n", strconv.Itoa(s.counts[i])},
+				})
+			}
+		}
+		endGroup(c)
+	}
+	return nil
+}
+
+// binLabel describes the range of values in bin i.
+func (h *Histogram[T]) binLabel(edges []float64, i int) string {
+	format := func(x float64) string {
+		return strconv.FormatFloat(x, 'g', 6, 64)
+	}
+	if kindOf[T]() == valueDuration {
+		format = FormatDuration(3)
+	}
+	closing := ")"
+	if i == len(edges)-2 {
+		closing = "]"
+	}
+	return "[" + format(edges[i]) + ", " + format(edges[i+1]) + closing
+}
+
+// Binning decides where to place the edges of a histogram's bins. The zero
+// Binning uses Sturges' rule.
+type Binning struct {
+	f func(sorted []float64) []float64
+
+	// err, if not nil, is reported when a histogram with this binning is
+	// rendered.
+	err error
+}
+
+// maxBins is the most bins a Binning that picks the number of bins itself
+// will use.
+const maxBins = 1000
+
+// edges returns the bin edges for sorted, in increasing order, or nil if
+// there are no values to bin.
+func (b Binning) edges(sorted []float64) []float64 {
+	if len(sorted) == 0 {
+		return nil
+	}
+	if b.f == nil {
+		return Sturges().f(sorted)
+	}
+	return b.f(sorted)
+}
+
+// BinCount splits the range of the values into n bins of equal width.
+func BinCount(n int) Binning {
+	return Binning{f: func(sorted []float64) []float64 {
+		lo, hi := binDomain(sorted)
+		return evenEdges(lo, hi, max(n, 1))
+	}}
+}
+
+// BinWidth splits the values into bins of width w, with edges at multiples
+// of w. w must be positive. If that would take more than 1000 bins, the
+// bins are widened to a multiple of w.
+func BinWidth(w float64) Binning {
+	if !(w > 0) || math.IsInf(w, 0) {
+		return Binning{err: fmt.Errorf("histogram bin width must be positive and finite, got %v", w)}
+	}
+	return Binning{f: func(sorted []float64) []float64 {
+		lo, hi := binDomain(sorted)
+		w := w
+		if n := math.Floor((hi-math.Floor(lo/w)*w)/w) + 1; n > maxBins {
+			w *= math.Ceil(n / maxBins)
+		}
+		start := math.Floor(lo/w) * w
+		n := int(math.Floor((hi-start)/w)) + 1
+		if start+float64(n-1)*w == hi && n > 1 {
+			// hi falls on an edge, and the last bin includes it.
+			n--
+		}
+		edges := make([]float64, n+1)
+		for i := range edges {
+			edges[i] = start + float64(i)*w
+		}
+		return edges
+	}}
+}
+
+// Sturges picks a number of bins of equal width using Sturges' rule,
+// ⌈log₂ n⌉ + 1 for n values. It works well for small, roughly normal
+// samples.
+func Sturges() Binning {
+	return Binning{f: func(sorted []float64) []float64 {
+		lo, hi := binDomain(sorted)
+		n := int(math.Ceil(math.Log2(float64(len(sorted))))) + 1
+		return evenEdges(lo, hi, n)
+	}}
+}
+
+// FreedmanDiaconis picks a bin width using the Freedman–Diaconis rule,
+// 2·IQR/∛n for n values with interquartile range IQR. Because it's based on
+// the IQR, it's robust to outliers, which makes it a good fit for large
+// samples and long-tailed distributions. It falls back to Sturges' rule if
+// the IQR is zero, and uses at most 1000 bins.
+func FreedmanDiaconis() Binning {
+	return Binning{f: func(sorted []float64) []float64 {
+		iqr := quantile(sorted, 0.75) - quantile(sorted, 0.25)
+		if iqr == 0 {
+			return Sturges().f(sorted)
+		}
+		lo, hi := binDomain(sorted)
+		w := 2 * iqr / math.Cbrt(float64(len(sorted)))
+		n := int(math.Ceil((hi - lo) / w))
+		return evenEdges(lo, hi, min(max(n, 1), maxBins))
+	}}
+}
+
+// LogBins applies b to the logarithms of the values, so that bins are
+// evenly spaced on a log scale. Use it with a logarithmic X axis. Values
+// that are zero or negative are left out.
+func LogBins(base int, b Binning) Binning {
+	if b.err != nil {
+		return b
+	}
+	log := logFunc(base)
+	return Binning{f: func(sorted []float64) []float64 {
+		i, _ := slices.BinarySearch(sorted, math.SmallestNonzeroFloat64)
+		logs := make([]float64, 0, len(sorted)-i)
+		for _, v := range sorted[i:] {
+			logs = append(logs, log(v))
+		}
+		edges := b.edges(logs)
+		for i, e := range edges {
+			edges[i] = math.Pow(float64(base), e)
+		}
+		if len(edges) != 0 {
+			// Rounding may leave the extreme values just outside.
+			edges[0] = min(edges[0], sorted[i])
+			edges[len(edges)-1] = max(edges[len(edges)-1], sorted[len(sorted)-1])
+		}
+		return edges
+	}}
+}
+
+// binDomain returns the range of the sorted values, widened if it's empty
+// so that there's room for at least one bin.
+func binDomain(sorted []float64) (lo, hi float64) {
+	lo, hi = sorted[0], sorted[len(sorted)-1]
+	if lo == hi {
+		lo, hi = lo-0.5, hi+0.5
+	}
+	return lo, hi
+}
+
+// evenEdges returns the edges of n bins of equal width covering [lo, hi].
+func evenEdges(lo, hi float64, n int) []float64 {
+	edges := make([]float64, n+1)
+	for i := range edges {
+		edges[i] = lo + (hi-lo)*float64(i)/float64(n)
+	}
+	edges[n] = hi
+	return edges
+}
+
+// quantile returns the q'th quantile of sorted, interpolating linearly
+// between values.
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	i := int(pos)
+	if i+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	frac := pos - float64(i)
+	return sorted[i]*(1-frac) + sorted[i+1]*frac
+}
@@ -0,0 +1,192 @@
+package ggg
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBinning(t *testing.T) {
+	type test struct {
+		name   string
+		bins   Binning
+		values []float64
+		want   []float64
+	}
+	for _, ts := range []test{
+		{
+			name:   "Empty",
+			bins:   Binning{},
+			values: nil,
+			want:   nil,
+		},
+		{
+			name:   "Sturges",
+			bins:   Binning{},
+			values: []float64{0, 1, 2, 3, 4, 5, 6, 7},
+			want:   []float64{0, 1.75, 3.5, 5.25, 7},
+		},
+		{
+			name:   "Count",
+			bins:   BinCount(2),
+			values: []float64{1, 2, 3},
+			want:   []float64{1, 2, 3},
+		},
+		{
+			name:   "CountOneValue",
+			bins:   BinCount(1),
+			values: []float64{4},
+			want:   []float64{3.5, 4.5},
+		},
+		{
+			name:   "Width",
+			bins:   BinWidth(2),
+			values: []float64{1, 4.5},
+			want:   []float64{0, 2, 4, 6},
+		},
+		{
+			name:   "WidthOnEdge",
+			bins:   BinWidth(2),
+			values: []float64{0, 4},
+			want:   []float64{0, 2, 4},
+		},
+		{
+			name:   "WidthCapped",
+			bins:   BinWidth(1),
+			values: []float64{0, 4000},
+			// Bins of width 1 would number over 1000, so they're widened.
+			want: multiples(5, 801),
+		},
+		{
+			name:   "FreedmanDiaconis",
+			bins:   FreedmanDiaconis(),
+			values: []float64{0, 1, 2, 3, 4, 5, 6, 7},
+			want:   []float64{0, 3.5, 7},
+		},
+		{
+			name:   "FreedmanDiaconisNoSpread",
+			bins:   FreedmanDiaconis(),
+			values: []float64{1, 1, 1, 1},
+			want:   []float64{0.5, 5.0 / 6, 7.0 / 6, 1.5},
+		},
+		{
+			name:   "Log",
+			bins:   LogBins(10, BinCount(3)),
+			values: []float64{-1, 0, 1, 1000},
+			want:   []float64{1, 10, 100, 1000},
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			got := ts.bins.edges(ts.values)
+			if len(got) != len(ts.want) {
+				t.Fatalf("got %d edges, want %d", len(got), len(ts.want))
+			}
+			for i := range got {
+				if math.Abs(got[i]-ts.want[i]) > 1e-9*math.Max(1, math.Abs(ts.want[i])) {
+					t.Fatalf("got edges %v, want %v", got, ts.want)
+				}
+			}
+		})
+	}
+}
+
+// multiples returns the first n multiples of w, from zero.
+func multiples(w float64, n int) []float64 {
+	s := make([]float64, n)
+	for i := range s {
+		s[i] = w * float64(i)
+	}
+	return s
+}
+
+func TestBinIndex(t *testing.T) {
+	edges := []float64{0, 1, 2, 3}
+	for _, tc := range []struct {
+		v    float64
+		want int
+	}{
+		{-1, -1},
+		{0, 0},
+		{0.5, 0},
+		{1, 1},
+		{2.5, 2},
+		{3, 2},
+		{3.5, -1},
+		{math.NaN(), -1},
+	} {
+		if got := binIndex(edges, tc.v); got != tc.want {
+			t.Errorf("binIndex(%v, %v) = %d, want %d", edges, tc.v, got, tc.want)
+		}
+	}
+}
+
+func TestHistogramCounts(t *testing.T) {
+	colX := NewColumn[float64]("x")
+	colS := NewColumn[string]("s")
+	d := Empty()
+	d.AddColumn(colX)
+	d.AddColumn(colS)
+	for i, x := range []float64{0, 0.5, 1, 1.5, 2, 4} {
+		for row := range d.Grow(1) {
+			colX.Set(d, row, x)
+			colS.Set(d, row, []string{"a", "b"}[i%2])
+		}
+	}
+	h := &Histogram[float64]{Data: d, X: colX, Bins: BinWidth(2), Geom: Bar(NiceColors(colS), Constant(1.0))}
+	edges, ss := h.bins()
+	if want := []float64{0, 2, 4}; !slices.Equal(edges, want) {
+		t.Fatalf("got edges %v, want %v", edges, want)
+	}
+	if len(ss) != 2 || !slices.Equal(ss[0].counts, []int{2, 1}) || !slices.Equal(ss[1].counts, []int{2, 1}) {
+		t.Errorf("got series %v and %v, want counts [2 1] each", ss[0], ss[1])
+	}
+	if lo, hi := h.yRange(); lo != 0 || hi != 2 {
+		t.Errorf("got Y range [%v, %v], want [0, 2]", lo, hi)
+	}
+	h.Density = true
+	if _, hi := h.yRange(); hi != 2.0/(3*2) {
+		t.Errorf("got density %v, want %v", hi, 2.0/(3*2))
+	}
+}
+
+func TestHistogramErrors(t *testing.T) {
+	colX := NewColumn[float64]("x")
+	d := Empty()
+	d.AddColumn(colX)
+	for row := range d.Grow(3) {
+		colX.Set(d, row, float64(row))
+	}
+	type test struct {
+		name    string
+		hist    *Histogram[float64]
+		errLike string
+	}
+	for _, ts := range []test{
+		{
+			name:    "NoGeom",
+			hist:    &Histogram[float64]{Data: d, X: colX},
+			errLike: "no initialized Geom",
+		},
+		{
+			name:    "ZeroWidth",
+			hist:    &Histogram[float64]{Data: d, X: colX, Bins: BinWidth(0), Geom: Bar(NiceColors(colX), Constant(1.0))},
+			errLike: "bin width must be positive",
+		},
+		{
+			name:    "LogZeroWidth",
+			hist:    &Histogram[float64]{Data: d, X: colX, Bins: LogBins(10, BinWidth(-1)), Geom: Bar(NiceColors(colX), Constant(1.0))},
+			errLike: "bin width must be positive",
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			// Ranges are computed before rendering checks the histogram.
+			ts.hist.xRange()
+			ts.hist.yRange()
+			err := ts.hist.render(nil, nil, nil, nil, 1)
+			if err == nil || !strings.Contains(err.Error(), ts.errLike) {
+				t.Errorf("got error %v, want one like %q", err, ts.errLike)
+			}
+		})
+	}
+}
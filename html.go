@@ -0,0 +1,240 @@
+package ggg
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+)
+
+// RenderHTML renders the plot as a self-contained HTML document and writes
+// it to w. The plot is drawn at the default size and scaled to fit the
+// page. The document embeds the plot as SVG, along with a script that
+// shows the data underlying points on hover, toggles series by clicking
+// their legend entries, and zooms and pans along the X axis with the mouse
+// wheel and dragging. Double-clicking resets the zoom.
+func (p *Plot) RenderHTML(w io.Writer, theme string) error {
+	th, err := lookupTheme(theme)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, htmlHeader, html.EscapeString(p.opts.title), svgColor(th.BorderBackgroundColor))
+	hc := &htmlCanvas{svgCanvas: newSVGCanvas(bw, defaultWidth, defaultHeight)}
+	if err := p.draw(hc, th, defaultWidth, defaultHeight); err != nil {
+		return err
+	}
+	if err := hc.finish(); err != nil {
+		return err
+	}
+	points, err := json.Marshal(hc.points)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(bw, "<script type=\"application/json\" id=\"ggg-points\">%s</script>\n", points)
+	io.WriteString(bw, htmlFooter)
+	return bw.Flush()
+}
+
+// htmlCanvas is an SVG canvas that also records data points, so they can
+// be inspected interactively.
+type htmlCanvas struct {
+	*svgCanvas
+	points []htmlPoint
+}
+
+// htmlPoint is a data point as seen by the embedded script. Fields are
+// name-value pairs.
+type htmlPoint struct {
+	Series string      `json:"s"`
+	X      float64     `json:"x"`
+	Y      float64     `json:"y"`
+	Fields [][2]string `json:"f"`
+}
+
+func (hc *htmlCanvas) recordPoint(series string, x, y float64, fields []field) {
+	pt := htmlPoint{Series: series, X: x, Y: y}
+	for _, f := range fields {
+		pt.Fields = append(pt.Fields, [2]string{f.name, f.value})
+	}
+	hc.points = append(hc.points, pt)
+}
+
+const htmlHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+<style>
+body { margin: 0; background: %s; }
+svg { display: block; width: 100%%; height: auto; user-select: none; }
+.data path, .x-axis path { vector-effect: non-scaling-stroke; }
+.legend-entry { cursor: pointer; }
+.legend-entry.off { opacity: 0.35; }
+#ggg-tooltip {
+	position: absolute; display: none; pointer-events: none;
+	padding: 4px 8px; border-radius: 4px;
+	background: rgba(0, 0, 0, 0.8); color: #fff;
+	font: 12px sans-serif; white-space: pre;
+}
+</style>
+</head>
+<body>
+`
+
+const htmlFooter = `<div id="ggg-tooltip"></div>
+<script>
+(function() {
+	"use strict";
+	const svg = document.querySelector("svg");
+	const tooltip = document.getElementById("ggg-tooltip");
+	const points = JSON.parse(document.getElementById("ggg-points").textContent) || [];
+	const hidden = new Set();
+
+	// Zoom state: X coordinates in zoomed groups map to ox + k*x.
+	let k = 1, ox = 0;
+	const chart = svg.querySelector(".chart").getBBox();
+	const lo = chart.x, hi = chart.x + chart.width;
+
+	// Wrap the zoomed groups in untransformed groups that clip them to the
+	// chart's X range while zoomed.
+	const ns = "http://www.w3.org/2000/svg";
+	const clip = document.createElementNS(ns, "clipPath");
+	clip.id = "ggg-zoom-clip";
+	const clipRect = document.createElementNS(ns, "rect");
+	clipRect.setAttribute("x", lo);
+	clipRect.setAttribute("y", 0);
+	clipRect.setAttribute("width", chart.width);
+	clipRect.setAttribute("height", svg.viewBox.baseVal.height);
+	clip.appendChild(clipRect);
+	svg.appendChild(clip);
+	const zoomed = [], wrappers = [];
+	for (const g of svg.querySelectorAll(".x-axis, .data")) {
+		const wrap = document.createElementNS(ns, "g");
+		g.parentNode.insertBefore(wrap, g);
+		wrap.appendChild(g);
+		zoomed.push(g);
+		wrappers.push(wrap);
+	}
+
+	// Circles and text keep their shape, so they are scaled back about
+	// their centers.
+	const unscaled = [];
+	for (const g of zoomed) {
+		for (const el of g.querySelectorAll("circle, text")) {
+			const b = el.getBBox();
+			unscaled.push([el, b.x + b.width / 2]);
+		}
+	}
+
+	function applyZoom() {
+		ox = Math.min(lo - lo * k, Math.max(hi - hi * k, ox));
+		const t = k === 1 ? "" : "translate(" + ox + " 0) scale(" + k + " 1)";
+		for (const g of zoomed) {
+			g.setAttribute("transform", t);
+		}
+		for (const w of wrappers) {
+			if (k === 1) {
+				w.removeAttribute("clip-path");
+			} else {
+				w.setAttribute("clip-path", "url(#ggg-zoom-clip)");
+			}
+		}
+		for (const [el, cx] of unscaled) {
+			el.setAttribute("transform", k === 1 ? "" :
+				"translate(" + cx + " 0) scale(" + (1 / k) + " 1) translate(" + (-cx) + " 0)");
+		}
+	}
+
+	// toSVG converts a mouse event's position to SVG coordinates.
+	function toSVG(e) {
+		const pt = svg.createSVGPoint();
+		pt.x = e.clientX;
+		pt.y = e.clientY;
+		return pt.matrixTransform(svg.getScreenCTM().inverse());
+	}
+
+	svg.addEventListener("wheel", function(e) {
+		e.preventDefault();
+		const m = toSVG(e);
+		const k2 = Math.min(1000, Math.max(1, k * Math.pow(2, -e.deltaY / 200)));
+		ox = m.x - (m.x - ox) * k2 / k;
+		k = k2;
+		applyZoom();
+		hover(e);
+	}, {passive: false});
+
+	let drag = null;
+	svg.addEventListener("mousedown", function(e) {
+		drag = {x: toSVG(e).x, ox: ox};
+	});
+	window.addEventListener("mousemove", function(e) {
+		if (drag) {
+			ox = drag.ox + toSVG(e).x - drag.x;
+			applyZoom();
+		}
+	});
+	window.addEventListener("mouseup", function() {
+		drag = null;
+	});
+	svg.addEventListener("dblclick", function() {
+		k = 1;
+		ox = 0;
+		applyZoom();
+	});
+
+	// Show the nearest visible point within a few pixels of the mouse.
+	function hover(e) {
+		const m = toSVG(e);
+		const scale = svg.getScreenCTM().a;
+		let best = null, bestDist = 15 / scale;
+		for (const p of points) {
+			if (hidden.has(p.s)) {
+				continue;
+			}
+			const x = ox + k * p.x;
+			if (x < lo || x > hi) {
+				continue;
+			}
+			const d = Math.hypot(x - m.x, p.y - m.y);
+			if (d < bestDist) {
+				best = p;
+				bestDist = d;
+			}
+		}
+		if (!best) {
+			tooltip.style.display = "none";
+			return;
+		}
+		tooltip.textContent = best.f.map(function(f) { return f[0] + ": " + f[1]; }).join("\n");
+		tooltip.style.display = "block";
+		tooltip.style.left = (e.pageX + 12) + "px";
+		tooltip.style.top = (e.pageY + 12) + "px";
+	}
+	svg.addEventListener("mousemove", hover);
+	svg.addEventListener("mouseleave", function() {
+		tooltip.style.display = "none";
+	});
+
+	// Clicking a legend entry toggles its series.
+	for (const entry of svg.querySelectorAll(".legend-entry")) {
+		entry.addEventListener("click", function() {
+			const s = entry.dataset.series;
+			const off = !hidden.has(s);
+			if (off) {
+				hidden.add(s);
+			} else {
+				hidden.delete(s);
+			}
+			entry.classList.toggle("off", off);
+			for (const g of svg.querySelectorAll('.series[data-series="' + CSS.escape(s) + '"]')) {
+				g.style.display = off ? "none" : "";
+			}
+		});
+	}
+})();
+</script>
+</body>
+</html>
+`
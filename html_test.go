@@ -0,0 +1,125 @@
+package ggg_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	. "github.com/mknyszek/ggg"
+	_ "github.com/mknyszek/ggg/themes/dark"
+)
+
+func TestRenderHTML(t *testing.T) {
+	// Series names that would break out of the document if they weren't
+	// escaped.
+	hostile := []string{`</script><script>alert("x")</script>`, `" onclick="alert('y')`}
+	colX := NewColumn[float64]("x")
+	colY := NewColumn[float64]("y")
+	colS := NewColumn[string]("name")
+	d := Empty()
+	d.AddColumn(colX)
+	d.AddColumn(colY)
+	d.AddColumn(colS)
+	for row := range d.Grow(6) {
+		colX.Set(d, row, float64(row))
+		colY.Set(d, row, float64(row*row))
+		colS.Set(d, row, hostile[row%2])
+	}
+	p := ScatterPlot(d, colX, colY, colS).Presentation(Title("<b>Fish</b> & chips"))
+	var buf bytes.Buffer
+	if err := p.RenderHTML(&buf, "dark"); err != nil {
+		t.Fatal(err)
+	}
+	doc := buf.String()
+
+	if !strings.Contains(doc, "<title>&lt;b&gt;Fish&lt;/b&gt; &amp; chips</title>") {
+		t.Errorf("title isn't escaped")
+	}
+	// Only the points and the script itself end a script element.
+	if n := strings.Count(doc, "</script>"); n != 2 {
+		t.Errorf("got %d ends of script elements, want 2", n)
+	}
+	if strings.Contains(doc, `alert("x")`) {
+		t.Errorf("series name appears unescaped in document")
+	}
+
+	// The document is self-contained, and only refers to the SVG namespace.
+	for _, m := range regexp.MustCompile(`(?:https?:)?//[\w.-]+\.\w+[^\s"']*`).FindAllString(doc, -1) {
+		if m != "http://www.w3.org/2000/svg" {
+			t.Errorf("document refers to %s", m)
+		}
+	}
+	if regexp.MustCompile(`\s(src|href)=`).MatchString(doc) {
+		t.Errorf("document loads an external resource")
+	}
+
+	// Each point is recorded with its series and the fields of its row.
+	m := regexp.MustCompile(`(?s)<script type="application/json" id="ggg-points">(.*?)</script>`).FindStringSubmatch(doc)
+	if m == nil {
+		t.Fatal("no points in document")
+	}
+	var points []struct {
+		S    string
+		X, Y float64
+		F    [][2]string
+	}
+	if err := json.Unmarshal([]byte(m[1]), &points); err != nil {
+		t.Fatalf("points aren't JSON: %v", err)
+	}
+	if len(points) != d.Rows() {
+		t.Fatalf("got %d points, want %d", len(points), d.Rows())
+	}
+	want := make(map[string]string)
+	for row := range d.Rows() {
+		want[fmt.Sprint(colX.Get(d, row))] = hostile[row%2]
+	}
+	for _, pt := range points {
+		if len(pt.F) != 3 || pt.F[0][0] != "x" || pt.F[1][0] != "y" || pt.F[2][0] != "name" {
+			t.Errorf("got fields %q, want x, y and name", pt.F)
+			continue
+		}
+		if s, ok := want[pt.F[0][1]]; !ok || pt.S != s || pt.F[2][1] != s {
+			t.Errorf("got point at x = %s in series %q with name %q, want %q", pt.F[0][1], pt.S, pt.F[2][1], s)
+		}
+		delete(want, pt.F[0][1])
+	}
+	if len(want) != 0 {
+		t.Errorf("no points for rows at x = %v", want)
+	}
+	// Points are in SVG coordinates, increasing to the right and down.
+	for i := 1; i < len(points); i++ {
+		if points[i].S == points[i-1].S && (points[i].X <= points[i-1].X || points[i].Y >= points[i-1].Y) {
+			t.Errorf("point %d at (%v, %v) doesn't follow point %d at (%v, %v)",
+				i, points[i].X, points[i].Y, i-1, points[i-1].X, points[i-1].Y)
+		}
+	}
+
+	// Series and their legend entries are marked with the same name, for
+	// the script to toggle them.
+	start, end := strings.Index(doc, "<svg"), strings.Index(doc, "</svg>")
+	if start < 0 || end < 0 {
+		t.Fatal("no SVG in document")
+	}
+	var root xmlNode
+	if err := xml.Unmarshal([]byte(doc[start:end+len("</svg>")]), &root); err != nil {
+		t.Fatalf("SVG isn't XML: %v", err)
+	}
+	var series, entries []string
+	for _, g := range root.find("g", "series") {
+		series = append(series, g.attr("data-series"))
+	}
+	for _, g := range root.find("g", "legend-entry") {
+		entries = append(entries, g.attr("data-series"))
+	}
+	if !slices.Equal(series, hostile) {
+		t.Errorf("got series %q, want %q", series, hostile)
+	}
+	if !slices.Equal(entries, hostile) {
+		t.Errorf("got legend entries %q, want %q", entries, hostile)
+	}
+}
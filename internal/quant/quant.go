@@ -0,0 +1,169 @@
+// Package quant reduces images to a limited palette of colors.
+package quant
+
+import (
+	"cmp"
+	"image"
+	"image/color"
+	"slices"
+)
+
+// Palette returns a palette of at most n colors representing the colors in
+// img. If img has no more than n distinct colors, the palette contains
+// exactly those colors. Otherwise, the colors are chosen by median cut.
+// The result depends only on the pixels of img.
+func Palette(img image.Image, n int) color.Palette {
+	hist := histogram(img)
+	colors := make([]entry, 0, len(hist))
+	for c, count := range hist {
+		colors = append(colors, entry{c, count})
+	}
+	slices.SortFunc(colors, func(a, b entry) int {
+		return cmp.Compare(key(a.c), key(b.c))
+	})
+	if len(colors) <= n {
+		p := make(color.Palette, len(colors))
+		for i, e := range colors {
+			p[i] = e.c
+		}
+		return p
+	}
+
+	// Repeatedly split the box with the widest range of any channel at
+	// the median along that channel.
+	boxes := []box{newBox(colors)}
+	for len(boxes) < n {
+		i := slices.IndexFunc(boxes, func(b box) bool { return len(b.colors) > 1 })
+		if i < 0 {
+			break
+		}
+		for j, b := range boxes {
+			if len(b.colors) > 1 && b.spread > boxes[i].spread {
+				i = j
+			}
+		}
+		lo, hi := boxes[i].split()
+		boxes[i] = lo
+		boxes = append(boxes, hi)
+	}
+	p := make(color.Palette, len(boxes))
+	for i, b := range boxes {
+		p[i] = b.average()
+	}
+	return p
+}
+
+// Paletted returns img converted to p, with each color mapped to the
+// nearest color in p.
+func Paletted(img image.Image, p color.Palette) *image.Paletted {
+	b := img.Bounds()
+	dst := image.NewPaletted(b, p)
+	cache := make(map[color.RGBA]uint8)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+			i, ok := cache[c]
+			if !ok {
+				i = uint8(p.Index(c))
+				cache[c] = i
+			}
+			dst.SetColorIndex(x, y, i)
+		}
+	}
+	return dst
+}
+
+type entry struct {
+	c     color.RGBA
+	count int
+}
+
+func histogram(img image.Image) map[color.RGBA]int {
+	hist := make(map[color.RGBA]int)
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			hist[color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)]++
+		}
+	}
+	return hist
+}
+
+func key(c color.RGBA) uint32 {
+	return uint32(c.R)<<24 | uint32(c.G)<<16 | uint32(c.B)<<8 | uint32(c.A)
+}
+
+// box is a set of colors, along with the channel over which they're most
+// spread out.
+type box struct {
+	colors  []entry
+	channel int
+	spread  int
+}
+
+func channel(c color.RGBA, i int) uint8 {
+	switch i {
+	case 0:
+		return c.R
+	case 1:
+		return c.G
+	case 2:
+		return c.B
+	}
+	return c.A
+}
+
+func newBox(colors []entry) box {
+	b := box{colors: colors, spread: -1}
+	for ch := range 4 {
+		lo, hi := uint8(255), uint8(0)
+		for _, e := range colors {
+			v := channel(e.c, ch)
+			lo, hi = min(lo, v), max(hi, v)
+		}
+		if s := int(hi) - int(lo); s > b.spread {
+			b.channel, b.spread = ch, s
+		}
+	}
+	return b
+}
+
+// split divides the box in two at the weighted median of its widest
+// channel. The box must contain at least two colors.
+func (b box) split() (lo, hi box) {
+	slices.SortStableFunc(b.colors, func(x, y entry) int {
+		return cmp.Compare(channel(x.c, b.channel), channel(y.c, b.channel))
+	})
+	var total int
+	for _, e := range b.colors {
+		total += e.count
+	}
+	i, sum := 0, 0
+	for i < len(b.colors)-1 {
+		sum += b.colors[i].count
+		i++
+		if 2*sum >= total {
+			break
+		}
+	}
+	return newBox(b.colors[:i]), newBox(b.colors[i:])
+}
+
+// average returns the mean of the colors in the box, weighted by their
+// frequency.
+func (b box) average() color.RGBA {
+	var r, g, bl, a, total int
+	for _, e := range b.colors {
+		r += int(e.c.R) * e.count
+		g += int(e.c.G) * e.count
+		bl += int(e.c.B) * e.count
+		a += int(e.c.A) * e.count
+		total += e.count
+	}
+	return color.RGBA{
+		R: uint8((r + total/2) / total),
+		G: uint8((g + total/2) / total),
+		B: uint8((bl + total/2) / total),
+		A: uint8((a + total/2) / total),
+	}
+}
@@ -0,0 +1,71 @@
+package ggg
+
+// labelPlacer draws labels, keeping track of the space they take up so
+// that labels that repel others can avoid it.
+type labelPlacer struct {
+	placed []labelBox
+}
+
+type labelBox struct {
+	x0, y0, x1, y1 float64
+}
+
+func (b labelBox) overlaps(o labelBox) bool {
+	return b.x0 < o.x1 && o.x0 < b.x1 && b.y0 < o.y1 && o.y0 < b.y1
+}
+
+// repelDirections are the directions to try moving a label in, in order of
+// preference.
+var repelDirections = [][2]float64{
+	{0, -1}, {1, 0}, {-1, 0}, {0, 1},
+	{1, -1}, {-1, -1}, {1, 1}, {-1, 1},
+}
+
+// draw draws s set in tf, centered on (x, y). If repel is set, it's instead
+// placed near (x, y) where it doesn't overlap the labels and anchor points
+// already placed, with a line back to (x, y) if it ends up far away.
+func (lp *labelPlacer) draw(c canvas, tf typeface, s string, x, y float64, repel bool, scaleFactor float64) {
+	w, h := measureText(c, tf, s)
+	at := func(cx, cy float64) labelBox {
+		return labelBox{cx - w/2, cy - h/2, cx + w/2, cy + h/2}
+	}
+	if !repel {
+		drawText(c, tf, s, x, y, 0.5, 0.35)
+		lp.placed = append(lp.placed, at(x, y))
+		return
+	}
+
+	gap := h / 4
+	lp.placed = append(lp.placed, labelBox{x - gap, y - gap, x + gap, y + gap})
+	cx, cy := x, y-h/2-gap
+	far := false
+search:
+	for k := range 8 {
+		for _, dir := range repelDirections {
+			tx := x + dir[0]*(w/2+gap+float64(k)*w/2)
+			ty := y + dir[1]*(h/2+gap+float64(k)*h)
+			b := at(tx, ty)
+			free := true
+			for _, p := range lp.placed {
+				if b.overlaps(p) {
+					free = false
+					break
+				}
+			}
+			if free {
+				cx, cy, far = tx, ty, k > 0
+				break search
+			}
+		}
+	}
+	b := at(cx, cy)
+	lp.placed = append(lp.placed, b)
+	if far {
+		// Point back at the anchor from the nearest point on the label.
+		c.moveTo(x, y)
+		c.lineTo(min(max(x, b.x0), b.x1), min(max(y, b.y0), b.y1))
+		c.setLineWidth(scaleFactor)
+		c.stroke()
+	}
+	drawText(c, tf, s, cx, cy, 0.5, 0.35)
+}
@@ -0,0 +1,74 @@
+package ggg
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLabelPlacer(t *testing.T) {
+	th := testTheme(t)
+	c := newRasterCanvas(600, 400)
+	tf := typeface{th.AnnotationFont, 14}
+
+	type test struct {
+		name string
+
+		// anchors are the points each label is placed near.
+		anchors [][2]float64
+	}
+	for _, ts := range []test{
+		{
+			name:    "SamePoint",
+			anchors: [][2]float64{{300, 200}, {300, 200}, {300, 200}, {300, 200}, {300, 200}, {300, 200}},
+		},
+		{
+			name:    "Row",
+			anchors: [][2]float64{{100, 200}, {105, 200}, {110, 200}, {115, 200}, {120, 200}},
+		},
+		{
+			name:    "Apart",
+			anchors: [][2]float64{{100, 100}, {500, 300}},
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			var lp labelPlacer
+			var labels []labelBox
+			for i, a := range ts.anchors {
+				lp.draw(c, tf, fmt.Sprintf("label %d", i), a[0], a[1], true, 1)
+				labels = append(labels, lp.placed[len(lp.placed)-1])
+			}
+			// No label overlaps another, or the anchor points placed
+			// before it.
+			for i, b := range labels {
+				for j, o := range labels[:i] {
+					if b.overlaps(o) {
+						t.Errorf("label %d at %+v overlaps label %d at %+v", i, b, j, o)
+					}
+				}
+				for j, a := range ts.anchors[:i+1] {
+					if a[0] > b.x0 && a[0] < b.x1 && a[1] > b.y0 && a[1] < b.y1 {
+						t.Errorf("label %d at %+v covers anchor %d", i, b, j)
+					}
+				}
+			}
+			// Labels with room to spare are placed just above their anchors.
+			if ts.name == "Apart" {
+				for i, b := range labels {
+					a := ts.anchors[i]
+					if cx := (b.x0 + b.x1) / 2; cx != a[0] || b.y1 >= a[1] {
+						t.Errorf("label %d at %+v, want it just above %v", i, b, a)
+					}
+				}
+			}
+		})
+	}
+
+	// Without repelling, labels are centered on their anchors, even if
+	// they overlap.
+	var lp labelPlacer
+	lp.draw(c, tf, "one", 300, 200, false, 1)
+	lp.draw(c, tf, "two", 300, 200, false, 1)
+	if b, o := lp.placed[0], lp.placed[1]; !b.overlaps(o) || (b.x0+b.x1)/2 != 300 {
+		t.Errorf("got labels at %+v and %+v, want both centered on (300, 200)", b, o)
+	}
+}
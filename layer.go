@@ -2,64 +2,144 @@ package ggg
 
 import (
 	"fmt"
-	"image"
 	"iter"
 	"math"
 	"sort"
-
-	"github.com/fogleman/gg"
+	"strconv"
+	"strings"
 )
 
 type AnyLayer interface {
 	xRange() (lo, hi float64)
 	yRange() (lo, hi float64)
-	render(theme *Theme, width, height int, xScale, yScale scaleFunc) (image.Image, error)
+	xKind() valueKind
+	yKind() valueKind
+	legend(theme *Theme) []legendEntry
+	dataset() *Dataset
+	where(f Filter) AnyLayer
+	render(c canvas, theme *Theme, xScale, yScale scaleFunc, scaleFactor float64) error
 }
 
-type Layer[X, Y Scalar] struct {
+type Layer[X, Y Value] struct {
 	Data *Dataset
 	X    Column[X]
 	Y    Column[Y]
 	Stat Statistic[Y]
 	Geom *Geom
+
+	// filter, if not nil, restricts the rows that are drawn.
+	filter Filter
 }
 
 func (l *Layer[X, Y]) xRange() (lo, hi float64) {
-	return colRange(l.Data, l.X)
+	lo, hi = colRange(l.Data, l.X)
+	if l.Geom != nil && (l.Geom.kind == kindBar || l.Geom.kind == kindBox || l.Geom.kind == kindTile) {
+		// Make room for the widest bar, box or tile.
+		w := l.Geom.maxSize(l.Data)
+		lo, hi = lo-w/2, hi+w/2
+	}
+	if l.Geom != nil && l.Geom.xEnd != nil {
+		elo, ehi := endRange(l.Data, l.Geom.xEnd)
+		lo, hi = min(lo, elo), max(hi, ehi)
+	}
+	return lo, hi
 }
 
 func (l *Layer[X, Y]) yRange() (lo, hi float64) {
-	return colRange(l.Data, l.Y)
+	lo, hi = colRange(l.Data, l.Y)
+	if l.Geom != nil && l.Geom.kind == kindBar {
+		// Bars start at zero.
+		lo, hi = min(lo, 0), max(hi, 0)
+	}
+	if l.Geom != nil && (l.Geom.kind == kindTile || l.Geom.kind == kindSpan) {
+		// Make room for the tallest tile or span.
+		h := maxOf(l.Data, l.Geom.height)
+		lo, hi = lo-h/2, hi+h/2
+	}
+	if l.Geom != nil && l.Geom.yEnd != nil {
+		elo, ehi := endRange(l.Data, l.Geom.yEnd)
+		lo, hi = min(lo, elo), max(hi, ehi)
+	}
+	return lo, hi
+}
+
+func (l *Layer[X, Y]) xKind() valueKind {
+	return kindOf[X]()
+}
+
+func (l *Layer[X, Y]) yKind() valueKind {
+	return kindOf[Y]()
 }
 
-func (l *Layer[X, Y]) render(theme *Theme, width, height int, xScale, yScale scaleFunc) (image.Image, error) {
+func (l *Layer[X, Y]) dataset() *Dataset {
+	return l.Data
+}
+
+// where returns a copy of the layer that only draws rows accepted by f.
+func (l *Layer[X, Y]) where(f Filter) AnyLayer {
+	c := *l
+	if c.filter != nil {
+		f = And(c.filter, f)
+	}
+	c.filter = f
+	return &c
+}
+
+// rows returns an iterator over the rows to draw.
+func (l *Layer[X, Y]) rows() iter.Seq[int] {
+	return filteredRows(l.Data, l.filter)
+}
+
+// filteredRows returns an iterator over the rows of d accepted by f, or
+// all rows if f is nil.
+func filteredRows(d *Dataset, f Filter) iter.Seq[int] {
+	if f == nil {
+		return func(yield func(int) bool) {
+			for row := range d.Rows() {
+				if !yield(row) {
+					break
+				}
+			}
+		}
+	}
+	return d.Filter(f)
+}
+
+func (l *Layer[X, Y]) legend(theme *Theme) []legendEntry {
+	if l.Geom == nil || l.Data == nil {
+		return nil
+	}
+	return l.Geom.legend(l.Data, theme)
+}
+
+func (l *Layer[X, Y]) render(c canvas, theme *Theme, xScale, yScale scaleFunc, scaleFactor float64) error {
 	if l.Geom == nil || l.Geom.kind == kindBadGeom {
-		return nil, fmt.Errorf("no initialized Geom for layer")
+		return fmt.Errorf("no initialized Geom for layer")
+	}
+	if l.Geom.kind == kindViolin {
+		return fmt.Errorf("Violin geom can only be drawn by a Density layer")
 	}
 	if l.Data == nil {
-		return nil, fmt.Errorf("no intended dataset specified for layer")
+		return fmt.Errorf("no intended dataset specified for layer")
 	}
 	if !l.X.Valid() {
-		return nil, fmt.Errorf("no initialized X column for layer")
+		return fmt.Errorf("no initialized X column for layer")
 	}
 	if !l.Y.Valid() {
-		return nil, fmt.Errorf("no initialized Y column for layer")
+		return fmt.Errorf("no initialized Y column for layer")
 	}
 	if !l.Stat.Valid() && l.Geom.Dimensions() != 1 {
-		return nil, fmt.Errorf("no statistic provided, but expected more than one Y dimensions")
+		return fmt.Errorf("no statistic provided, but expected more than one Y dimensions")
 	}
 	if l.Stat.Valid() && l.Geom.Dimensions() != l.Stat.Dimensions() {
-		return nil, fmt.Errorf("dimensional mismatch: %d-dimensional geom, but %d-dimensional statistic", l.Geom.Dimensions(), l.Stat.Dimensions())
+		return fmt.Errorf("dimensional mismatch: %d-dimensional geom, but %d-dimensional statistic", l.Geom.Dimensions(), l.Stat.Dimensions())
 	}
 
-	c := gg.NewContext(width, height)
-	w, h := float64(width), float64(height)
-	scaleFactor := math.Round(math.Sqrt(w * h / (1080 * 720)))
 	n := 0
 	smap := make(map[any]*series[X, Y])
 	var ss []*series[X, Y]
 	// Split the data into series.
-	for row := range l.Data.Rows() {
+	for row := range l.rows() {
 		key := l.Geom.grouping(l.Data, row)
 		s, ok := smap[key]
 		if !ok {
@@ -71,37 +151,87 @@ func (l *Layer[X, Y]) render(theme *Theme, width, height int, xScale, yScale sca
 		s.rows = append(s.rows, row)
 	}
 	yBuf := make([]float64, l.Geom.Dimensions())
+	rec, _ := c.(pointRecorder)
+	labels := new(labelPlacer)
 	for _, s := range ss {
-		draw := l.Geom.drawer(theme, c, xScale, yScale, scaleFactor)
+		draw, flush := l.Geom.drawer(theme, c, xScale, yScale, scaleFactor, labels)
+		label := l.Geom.seriesLabel(l.Data, s.rows[0])
+		beginGroup(c, "series", label)
 
 		// Sort the rows by X then Y.
 		sort.Sort(s)
 
-		// No statistic, take all points.
 		if !l.Stat.Valid() {
+			// No statistic, take all points.
 			for _, row := range s.rows {
-				yBuf[0] = float64(l.Y.Get(l.Data, row))
-				draw(l.Data, row, float64(l.X.Get(l.Data, row)), yBuf)
+				x := toFloat(l.X.Get(l.Data, row))
+				yBuf[0] = toFloat(l.Y.Get(l.Data, row))
+				draw(l.Data, row, x, yBuf)
+				if rec != nil {
+					rec.recordPoint(label, xScale(x), yScale(yBuf[0]), l.Data.rowFields(row))
+				}
 			}
-			continue
-		}
-
-		// Apply statistic.
-		for row, ygroup := range group(l.Data, s.rows, l.X, l.Y) {
-			l.Stat.ApplyInto(func(yield func(Y) bool) {
-				for _, y := range ygroup {
-					if !yield(y) {
-						break
+		} else {
+			// Apply statistic.
+			for row, ygroup := range group(l.Data, s.rows, l.X, l.Y) {
+				l.Stat.ApplyInto(func(yield func(Y) bool) {
+					for _, y := range ygroup {
+						if !yield(y) {
+							break
+						}
 					}
+				}, yBuf)
+				x := toFloat(l.X.Get(l.Data, row))
+				draw(l.Data, row, x, yBuf)
+				recY := yBuf[0]
+				if l.Geom.kind == kindBox {
+					l.drawOutliers(c, theme, row, x, ygroup, yBuf[0], yBuf[4], xScale, yScale, scaleFactor)
+					recY = yBuf[2]
 				}
-			}, yBuf)
-			draw(l.Data, row, float64(l.X.Get(l.Data, row)), yBuf)
+				if rec != nil {
+					rec.recordPoint(label, xScale(x), yScale(recY), l.statFields(row, yBuf, len(ygroup)))
+				}
+			}
+		}
+		if flush != nil {
+			flush()
+		}
+		endGroup(c)
+	}
+	return nil
+}
+
+// drawOutliers draws a point for each of the values at x that fall outside
+// [lo, hi].
+func (l *Layer[X, Y]) drawOutliers(c canvas, theme *Theme, row int, x float64, values []Y, lo, hi float64, xScale, yScale scaleFunc, scaleFactor float64) {
+	c.setColor(l.Geom.color.scale(l.Data, row, theme))
+	for _, v := range values {
+		if y := toFloat(v); y < lo || y > hi {
+			c.circle(xScale(x), yScale(y), 3*scaleFactor)
+			c.fill()
+		}
+	}
+}
+
+// statFields describes a data point produced by applying the layer's
+// statistic to n values.
+func (l *Layer[X, Y]) statFields(row int, y []float64, n int) []field {
+	ys := make([]string, len(y))
+	for i, v := range y {
+		ys[i] = strconv.FormatFloat(v, 'g', 6, 64)
+	}
+	fields := []field{{l.X.Name(), fmt.Sprintf("%v", l.X.Get(l.Data, row))}}
+	if names := l.Stat.names; names != nil {
+		for i, name := range names {
+			fields = append(fields, field{name, ys[i]})
 		}
+	} else {
+		fields = append(fields, field{l.Y.Name(), strings.Join(ys, " – ")})
 	}
-	return c.Image(), nil
+	return append(fields, field{"n", strconv.Itoa(n)})
 }
 
-func group[X, Y Scalar](d *Dataset, rows []int, x Column[X], y Column[Y]) iter.Seq2[int, []Y] {
+func group[X, Y Value](d *Dataset, rows []int, x Column[X], y Column[Y]) iter.Seq2[int, []Y] {
 	return func(yield func(int, []Y) bool) {
 		var lastX X
 		var lastRow int
@@ -116,7 +246,9 @@ func group[X, Y Scalar](d *Dataset, rows []int, x Column[X], y Column[Y]) iter.S
 				ys = []Y{y.Get(d, r)}
 				continue
 			}
-			if lastX != x {
+			// Compare rather than test equality, so that equal instants
+			// in different locations are grouped together.
+			if compareValues(lastX, x) != 0 {
 				if !yield(lastRow, ys) {
 					return
 				}
@@ -137,7 +269,7 @@ func group[X, Y Scalar](d *Dataset, rows []int, x Column[X], y Column[Y]) iter.S
 	}
 }
 
-type series[X, Y Scalar] struct {
+type series[X, Y Value] struct {
 	d    *Dataset
 	rows []int
 	x    Column[X]
@@ -154,18 +286,18 @@ func (s *series[X, Y]) Swap(i, j int) {
 
 func (s *series[X, Y]) Less(i, j int) bool {
 	xi, xj := s.x.Get(s.d, s.rows[i]), s.x.Get(s.d, s.rows[j])
-	if xi == xj {
-		yi, yj := s.y.Get(s.d, s.rows[i]), s.y.Get(s.d, s.rows[j])
-		return yi < yj
+	if c := compareValues(xi, xj); c != 0 {
+		return c < 0
 	}
-	return xi < xj
+	yi, yj := s.y.Get(s.d, s.rows[i]), s.y.Get(s.d, s.rows[j])
+	return compareValues(yi, yj) < 0
 }
 
-func colRange[T Scalar](d *Dataset, c Column[T]) (lo, hi float64) {
+func colRange[T Value](d *Dataset, c Column[T]) (lo, hi float64) {
 	hi = math.Inf(-1)
 	lo = math.Inf(1)
 	for value := range c.All(d) {
-		v := float64(value)
+		v := toFloat(value)
 		if v < lo {
 			lo = v
 		}
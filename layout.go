@@ -0,0 +1,232 @@
+package ggg
+
+import "math"
+
+// lineSpacing is the spacing of lines of wrapped text around a plot,
+// relative to the height of their font.
+const lineSpacing = 1.2
+
+// plotLayout is the arrangement of a plot, with its chart area sized to
+// leave exactly enough room for the text around it.
+type plotLayout struct {
+	titleFont, subtitleFont, axisFont, annotationFont typeface
+
+	// margin is the space around the edge of the plot and between its
+	// parts. tick is the length of ticks, and half the space between the
+	// chart area and tick labels.
+	margin, tick float64
+
+	area chartArea
+
+	// radius is the radius of the circle polar plots are drawn in, at the
+	// center of the chart area.
+	radius float64
+
+	// textWidth is the width titles and the caption are wrapped to, and
+	// the tops of each.
+	textWidth                   float64
+	titleY, subtitleY, captionY float64
+
+	// frameWidth is the width left for the frame label, to the right of
+	// the title.
+	frameWidth float64
+
+	// The top of the bottom axis's title, and the centers of the left and
+	// right axes' titles, along with the widths they're wrapped to.
+	bottomTitleY, leftTitleX, rightTitleX float64
+	bottomTitleWidth, leftTitleWidth      float64
+}
+
+// bottomLeft returns the axes along the bottom and left of the plot.
+// Flipping the plot moves the X axis to the left.
+func (p *Plot) bottomLeft() (bottom, left *axis) {
+	if p.opts.coord == coordFlip {
+		return &p.opts.y, &p.opts.x
+	}
+	return &p.opts.x, &p.opts.y
+}
+
+// layout measures the text around the plot and arranges it, filling a w
+// by h area. y2 is set if the plot uses the secondary Y axis. The plot's
+// ranges must already have been computed.
+func (p *Plot) layout(c canvas, th *Theme, w, h float64, y2 bool) *plotLayout {
+	scale := h
+	if p.opts.textScale > 0 {
+		scale *= p.opts.textScale
+	}
+	l := &plotLayout{
+		titleFont:      typeface{th.TitleFont, math.Round(scale / 15)},
+		subtitleFont:   typeface{th.AxisFont, math.Round(scale / 24)},
+		axisFont:       typeface{th.AxisFont, math.Round(scale / 30)},
+		annotationFont: typeface{th.AnnotationFont, math.Round(scale / 50)},
+	}
+	fh := fontHeight(c, l.annotationFont)
+	l.margin = fh
+	l.tick = fh / 2
+	l.textWidth = w - 2*l.margin
+
+	// Measure the tick labels.
+	bottom, left := p.bottomLeft()
+	polar := p.opts.coord == coordPolar
+	var bottomTicks, leftTicks, rightTicks []float64
+	if len(p.layers) != 0 {
+		bottomTicks, leftTicks = bottom.ticks(), left.ticks()
+		if y2 {
+			rightTicks = p.opts.y2.ticks()
+		}
+	}
+	_, bottomLabels := l.labelsExtent(c, bottom, bottomTicks, bottom.labelAngle)
+	leftLabels, _ := l.labelsExtent(c, left, leftTicks, left.labelAngle)
+	rightLabels, _ := l.labelsExtent(c, &p.opts.y2, rightTicks, p.opts.y2.labelAngle)
+
+	// Titles, from the top down. The frame label sits to the right of
+	// the title.
+	y := l.margin
+	for _, label := range append([]string{p.opts.frame}, p.opts.frames...) {
+		fw, _ := measureText(c, l.axisFont, label)
+		l.frameWidth = max(l.frameWidth, fw)
+	}
+	if p.opts.title != "" || p.opts.frame != "" {
+		l.titleY = y
+		height := wrappedHeight(c, l.titleFont, p.opts.title, l.textWidth-l.frameWidth-l.margin, lineSpacing)
+		if p.opts.frame != "" {
+			height = max(height, fontHeight(c, l.axisFont))
+		}
+		y += height + l.margin/2
+	}
+	if p.opts.subtitle != "" {
+		l.subtitleY = y
+		y += wrappedHeight(c, l.subtitleFont, p.opts.subtitle, l.textWidth, lineSpacing) + l.margin/2
+	}
+	// Leave room for the top half of the topmost tick label.
+	top := y + fh/2
+
+	// The caption, from the bottom up.
+	y = h - l.margin
+	if p.opts.caption != "" {
+		y -= wrappedHeight(c, l.annotationFont, p.opts.caption, l.textWidth, lineSpacing)
+		l.captionY = y
+		y -= l.margin / 2
+	}
+	captionTop := y
+
+	// Axis titles are wrapped to the size of the chart area, which depends
+	// on the size of the titles, so measure them twice: once wrapped to
+	// the whole plot, and again wrapped to the chart area that leaves.
+	area := chartArea{0, top, w, captionTop}
+	for range 2 {
+		l.bottomTitleWidth = area.x1 - area.x0
+		l.leftTitleWidth = area.y1 - area.y0
+
+		// Bottom axis.
+		y := captionTop
+		if bottom.title != "" {
+			y -= wrappedHeight(c, l.titleFontOf(bottom), bottom.title, l.bottomTitleWidth, lineSpacing)
+			l.bottomTitleY = y
+		}
+		y -= l.margin / 2
+		if !polar && len(bottomTicks) != 0 {
+			y -= 2*l.tick + bottomLabels
+		}
+
+		// Left axis.
+		x := l.margin
+		if left.title != "" {
+			height := wrappedHeight(c, l.titleFontOf(left), left.title, l.leftTitleWidth, lineSpacing)
+			l.leftTitleX = x + height/2
+			x += height + l.margin
+		}
+		if !polar && len(leftTicks) != 0 {
+			x += leftLabels + 2*l.tick
+		}
+
+		// Right axis.
+		right := w - l.margin
+		if y2 {
+			if p.opts.y2.title != "" {
+				height := wrappedHeight(c, l.titleFontOf(&p.opts.y2), p.opts.y2.title, l.leftTitleWidth, lineSpacing)
+				l.rightTitleX = right - height/2
+				right -= height + l.margin
+			}
+			if len(rightTicks) != 0 {
+				right -= rightLabels + 2*l.tick
+			}
+		}
+		area = chartArea{x, top, right, y}
+	}
+
+	// Leave room for the right half of the bottom axis's tick labels.
+	if !y2 && !polar {
+		area.x1 -= l.overhang(c, bottom, bottomTicks, area.x1-area.x0)
+	}
+	l.area = area
+
+	if polar && len(p.layers) != 0 {
+		// Leave room for the X axis's tick labels around the circle, which
+		// aren't rotated.
+		xw, xh := l.labelsExtent(c, &p.opts.x, p.opts.x.ticks(), 0)
+		l.radius = max(1, min(
+			(area.x1-area.x0)/2-xw-fh/2,
+			(area.y1-area.y0)/2-xh-fh/2,
+		))
+	}
+	return l
+}
+
+// titleFontOf returns the font of a's title.
+func (l *plotLayout) titleFontOf(a *axis) typeface {
+	return scaleFont(l.axisFont, a.titleScale)
+}
+
+// labelFontOf returns the font of a's tick labels.
+func (l *plotLayout) labelFontOf(a *axis) typeface {
+	return scaleFont(l.annotationFont, a.labelScale)
+}
+
+// scaleFont returns tf scaled by f, if f isn't zero.
+func scaleFont(tf typeface, f float64) typeface {
+	if f <= 0 {
+		return tf
+	}
+	return typeface{tf.font, math.Round(tf.size * f)}
+}
+
+// labelsExtent returns the width and height of the bounding boxes of the
+// widest and tallest of a's labels for ticks, rotated by angle degrees.
+func (l *plotLayout) labelsExtent(c canvas, a *axis, ticks []float64, angle float64) (w, h float64) {
+	tf := l.labelFontOf(a)
+	for _, t := range ticks {
+		lw, lh := measureText(c, tf, a.tickLabel(t))
+		lw, lh = rotatedExtent(lw, lh, angle)
+		w, h = max(w, lw), max(h, lh)
+	}
+	return w, h
+}
+
+// overhang returns how far the labels of ticks along a, the bottom axis,
+// stick out past the right of a chart area length wide.
+func (l *plotLayout) overhang(c canvas, a *axis, ticks []float64, length float64) float64 {
+	frac, err := a.scale("", 0, 1)
+	if err != nil {
+		return 0
+	}
+	tf := l.labelFontOf(a)
+	sin, cos := math.Sincos(math.Abs(a.labelAngle) * math.Pi / 180)
+	var over float64
+	for _, t := range ticks {
+		lw, lh := measureText(c, tf, a.tickLabel(t))
+		// How far the label extends right of its tick, as anchored by
+		// drawAxes.
+		var right float64
+		switch {
+		case a.labelAngle == 0:
+			right = lw / 2
+		case a.labelAngle > 0:
+			right = lh / 2 * sin
+		default:
+			right = lw*cos + lh/2*sin
+		}
+		over = max(over, right-(1-frac(t))*length)
+	}
+	return max(0, over)
+}
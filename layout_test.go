@@ -0,0 +1,299 @@
+package ggg
+
+import (
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+)
+
+// testTheme returns a theme for rendering plots in tests, which can't
+// import the themes without an import cycle.
+func testTheme(t *testing.T) *Theme {
+	t.Helper()
+	f := parseRoboto(t)
+	return &Theme{
+		Name:                  "test",
+		ForegroundColor:       color.White,
+		GridlineColor:         color.Gray{0x40},
+		ChartBackgroundColor:  color.Black,
+		BorderBackgroundColor: color.Black,
+		SeriesPalette:         func(uint64) color.Color { return color.White },
+		TitleFont:             f,
+		AxisFont:              f,
+		AnnotationFont:        f,
+	}
+}
+
+// pointsPlot returns a scatter plot of y = x*x, for x from lo to hi.
+func pointsPlot(lo, hi int) *Plot {
+	d := Empty()
+	x, y := NewColumn[float64]("x"), NewColumn[float64]("y")
+	d.AddColumn(x)
+	d.AddColumn(y)
+	for row := range d.Grow(hi - lo + 1) {
+		v := float64(lo + row)
+		x.Set(d, row, v)
+		y.Set(d, row, v*v)
+	}
+	return NewPlot().Layer(&Layer[float64, float64]{
+		Data: d,
+		X:    x,
+		Y:    y,
+		Geom: Point(PaletteColor(0), Constant(3.0)),
+	})
+}
+
+func TestLayout(t *testing.T) {
+	const w, h = 600, 400
+	th := testTheme(t)
+	c := newRasterCanvas(w, h)
+	layout := func(p *Plot) (*plotLayout, *Plot) {
+		p = p.prepared()
+		if len(p.layers) != 0 {
+			p.computeRanges()
+		}
+		return p.layout(c, th, w, h, p.usesY2()), p
+	}
+	empty, _ := layout(NewPlot())
+	fh := fontHeight(c, empty.annotationFont)
+	margin, tick := empty.margin, empty.tick
+	if margin != fh || tick != fh/2 {
+		t.Fatalf("got margin %v and tick %v, want %v and %v", margin, tick, fh, fh/2)
+	}
+
+	type test struct {
+		name string
+		plot *Plot
+
+		// want returns the expected chart area, given the layout and the
+		// plot with its ranges computed.
+		want func(l *plotLayout, p *Plot) chartArea
+	}
+	for _, ts := range []test{
+		{
+			// With nothing to measure, the chart area is inset by the
+			// margins, and room for half a tick label at the top.
+			name: "Empty",
+			plot: NewPlot(),
+			want: func(l *plotLayout, p *Plot) chartArea {
+				return chartArea{margin, margin + fh/2, w - margin, h - 1.5*margin}
+			},
+		},
+		{
+			name: "Titles",
+			plot: NewPlot().Presentation(Title("Title"), Subtitle("Subtitle"), Caption("Caption")),
+			want: func(l *plotLayout, p *Plot) chartArea {
+				title := wrappedHeight(c, l.titleFont, "Title", l.textWidth, lineSpacing)
+				subtitle := wrappedHeight(c, l.subtitleFont, "Subtitle", l.textWidth, lineSpacing)
+				caption := wrappedHeight(c, l.annotationFont, "Caption", l.textWidth, lineSpacing)
+				if l.titleY != margin || l.subtitleY != margin+title+margin/2 {
+					t.Errorf("got title at %v and subtitle at %v", l.titleY, l.subtitleY)
+				}
+				if l.captionY != h-margin-caption {
+					t.Errorf("got caption at %v, want %v", l.captionY, h-margin-caption)
+				}
+				return chartArea{
+					margin,
+					l.subtitleY + subtitle + margin/2 + fh/2,
+					w - margin,
+					l.captionY - margin/2 - margin/2,
+				}
+			},
+		},
+		{
+			// Long titles wrap onto more lines.
+			name: "WrappedTitle",
+			plot: NewPlot().Presentation(Title(strings.Repeat("word ", 30))),
+			want: func(l *plotLayout, p *Plot) chartArea {
+				title := wrappedHeight(c, l.titleFont, strings.Repeat("word ", 30), l.textWidth, lineSpacing)
+				if title < 2*fontHeight(c, l.titleFont) {
+					t.Errorf("title is %v tall, want at least two lines", title)
+				}
+				return chartArea{margin, margin + title + margin/2 + fh/2, w - margin, h - 1.5*margin}
+			},
+		},
+		{
+			// The axes leave room for their titles, ticks and labels.
+			name: "Axes",
+			plot: pointsPlot(0, 10).Presentation(XAxis("x"), YAxis("y")),
+			want: func(l *plotLayout, p *Plot) chartArea {
+				xTitle := wrappedHeight(c, l.titleFontOf(&p.opts.x), "x", l.bottomTitleWidth, lineSpacing)
+				yTitle := wrappedHeight(c, l.titleFontOf(&p.opts.y), "y", l.leftTitleWidth, lineSpacing)
+				yLabels, _ := l.labelsExtent(c, &p.opts.y, p.opts.y.ticks(), 0)
+				_, xLabels := l.labelsExtent(c, &p.opts.x, p.opts.x.ticks(), 0)
+				if l.bottomTitleY != h-margin-xTitle {
+					t.Errorf("got X axis title at %v, want %v", l.bottomTitleY, h-margin-xTitle)
+				}
+				if l.leftTitleX != margin+yTitle/2 {
+					t.Errorf("got Y axis title at %v, want %v", l.leftTitleX, margin+yTitle/2)
+				}
+				x1 := w - margin
+				x1 -= l.overhang(c, &p.opts.x, p.opts.x.ticks(), x1-(margin+yTitle+margin+yLabels+2*tick))
+				return chartArea{
+					margin + yTitle + margin + yLabels + 2*tick,
+					margin + fh/2,
+					x1,
+					h - margin - xTitle - margin/2 - 2*tick - xLabels,
+				}
+			},
+		},
+		{
+			// The secondary Y axis leaves room on the right, and the
+			// bottom axis's labels may then overhang the chart area.
+			name: "Y2",
+			plot: pointsPlot(0, 10).Layer(pointsPlot(0, 1000).layers[0].AnyLayer, OnAxis(Y2)).Presentation(Y2Axis("y2")),
+			want: func(l *plotLayout, p *Plot) chartArea {
+				y2Title := wrappedHeight(c, l.titleFontOf(&p.opts.y2), "y2", l.leftTitleWidth, lineSpacing)
+				yLabels, _ := l.labelsExtent(c, &p.opts.y, p.opts.y.ticks(), 0)
+				y2Labels, _ := l.labelsExtent(c, &p.opts.y2, p.opts.y2.ticks(), 0)
+				_, xLabels := l.labelsExtent(c, &p.opts.x, p.opts.x.ticks(), 0)
+				if l.rightTitleX != w-margin-y2Title/2 {
+					t.Errorf("got Y2 axis title at %v, want %v", l.rightTitleX, w-margin-y2Title/2)
+				}
+				return chartArea{
+					margin + yLabels + 2*tick,
+					margin + fh/2,
+					w - margin - y2Title - margin - y2Labels - 2*tick,
+					h - margin - margin/2 - 2*tick - xLabels,
+				}
+			},
+		},
+		{
+			// Tick labels are measured in the font they're scaled to.
+			name: "TickLabelScale",
+			plot: pointsPlot(0, 10).Presentation(YAxis("", TickLabelScale(2))),
+			want: func(l *plotLayout, p *Plot) chartArea {
+				if got, want := l.labelFontOf(&p.opts.y).size, 2*l.annotationFont.size; got != want {
+					t.Errorf("got Y tick labels of size %v, want %v", got, want)
+				}
+				yLabels, _ := l.labelsExtent(c, &p.opts.y, p.opts.y.ticks(), 0)
+				_, xLabels := l.labelsExtent(c, &p.opts.x, p.opts.x.ticks(), 0)
+				x0 := margin + yLabels + 2*tick
+				return chartArea{
+					x0,
+					margin + fh/2,
+					w - margin - l.overhang(c, &p.opts.x, p.opts.x.ticks(), w-margin-x0),
+					h - margin - margin/2 - 2*tick - xLabels,
+				}
+			},
+		},
+		{
+			// Labels rotated upright take up as much height as they're
+			// wide, and overhang by half their height.
+			name: "RotatedTickLabels",
+			plot: pointsPlot(9990, 10000).Presentation(XAxis("", RotateTickLabels(90))),
+			want: func(l *plotLayout, p *Plot) chartArea {
+				x := &p.opts.x
+				wide, flat := l.labelsExtent(c, x, x.ticks(), 0)
+				_, xLabels := l.labelsExtent(c, x, x.ticks(), 90)
+				if math.Abs(xLabels-wide) > 1e-9 || xLabels <= flat {
+					t.Errorf("rotated labels are %v tall, want %v, their width, and more than %v", xLabels, wide, flat)
+				}
+				yLabels, _ := l.labelsExtent(c, &p.opts.y, p.opts.y.ticks(), 0)
+				x0 := margin + yLabels + 2*tick
+				if over := l.overhang(c, x, x.ticks(), w-margin-x0); math.Abs(over-flat/2) > 1e-9 {
+					t.Errorf("last label overhangs by %v, want half its height, %v", over, flat/2)
+				}
+				return chartArea{
+					x0,
+					margin + fh/2,
+					w - margin - flat/2,
+					h - margin - margin/2 - 2*tick - xLabels,
+				}
+			},
+		},
+		{
+			// Labels rotated clockwise start at their ticks, so the last
+			// one overhangs the right margin by more than half its width.
+			name: "RotatedTickLabelsOverhang",
+			plot: pointsPlot(9990, 10000).Presentation(XAxis("", RotateTickLabels(-30))),
+			want: func(l *plotLayout, p *Plot) chartArea {
+				x := &p.opts.x
+				_, xLabels := l.labelsExtent(c, x, x.ticks(), -30)
+				lw, lh := measureText(c, l.labelFontOf(x), x.tickLabel(10000))
+				yLabels, _ := l.labelsExtent(c, &p.opts.y, p.opts.y.ticks(), 0)
+				x0 := margin + yLabels + 2*tick
+				over := lw*math.Cos(math.Pi/6) + lh/2*math.Sin(math.Pi/6)
+				if over <= lw/2 {
+					t.Errorf("last label overhangs by %v, want more than %v", over, lw/2)
+				}
+				return chartArea{
+					x0,
+					margin + fh/2,
+					w - margin - over,
+					h - margin - margin/2 - 2*tick - xLabels,
+				}
+			},
+		},
+		{
+			// Titles and labels are measured in their scaled fonts.
+			name: "TextScale",
+			plot: pointsPlot(0, 10).Presentation(TextScale(2), Title("Title"), XAxis("x"), YAxis("y")),
+			want: func(l *plotLayout, p *Plot) chartArea {
+				fh := fontHeight(c, l.annotationFont)
+				margin, tick := fh, fh/2
+				title := wrappedHeight(c, l.titleFont, "Title", l.textWidth-l.margin, lineSpacing)
+				if unscaled := wrappedHeight(c, empty.titleFont, "Title", l.textWidth, lineSpacing); title < 1.8*unscaled {
+					t.Errorf("title is %v tall, want about twice %v", title, unscaled)
+				}
+				xTitle := wrappedHeight(c, l.titleFontOf(&p.opts.x), "x", l.bottomTitleWidth, lineSpacing)
+				yTitle := wrappedHeight(c, l.titleFontOf(&p.opts.y), "y", l.leftTitleWidth, lineSpacing)
+				yLabels, _ := l.labelsExtent(c, &p.opts.y, p.opts.y.ticks(), 0)
+				_, xLabels := l.labelsExtent(c, &p.opts.x, p.opts.x.ticks(), 0)
+				if unscaled := fontHeight(c, empty.annotationFont); xLabels < 1.8*unscaled {
+					t.Errorf("X tick labels are %v tall, want about twice %v", xLabels, unscaled)
+				}
+				x0 := margin + yTitle + margin + yLabels + 2*tick
+				return chartArea{
+					x0,
+					margin + title + margin/2 + fh/2,
+					w - margin - l.overhang(c, &p.opts.x, p.opts.x.ticks(), w-margin-x0),
+					h - margin - xTitle - margin/2 - 2*tick - xLabels,
+				}
+			},
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			l, p := layout(ts.plot)
+			got, want := l.area, ts.want(l, p)
+			const eps = 1e-9
+			if math.Abs(got.x0-want.x0) > eps || math.Abs(got.y0-want.y0) > eps ||
+				math.Abs(got.x1-want.x1) > eps || math.Abs(got.y1-want.y1) > eps {
+				t.Errorf("got chart area %+v, want %+v", got, want)
+			}
+		})
+	}
+
+	t.Run("TextScale", func(t *testing.T) {
+		l, _ := layout(NewPlot().Presentation(TextScale(2)))
+		for _, f := range []struct {
+			name      string
+			got, want typeface
+		}{
+			{"title", l.titleFont, empty.titleFont},
+			{"subtitle", l.subtitleFont, empty.subtitleFont},
+			{"axis", l.axisFont, empty.axisFont},
+			{"annotation", l.annotationFont, empty.annotationFont},
+		} {
+			if math.Abs(f.got.size-2*f.want.size) > 1 {
+				t.Errorf("got %s font of size %v, want about %v", f.name, f.got.size, 2*f.want.size)
+			}
+		}
+	})
+
+	t.Run("Polar", func(t *testing.T) {
+		l, p := layout(pointsPlot(0, 10).Presentation(CoordPolar()))
+		xw, xh := l.labelsExtent(c, &p.opts.x, p.opts.x.ticks(), 0)
+		area := l.area
+		want := min((area.x1-area.x0)/2-xw-fh/2, (area.y1-area.y0)/2-xh-fh/2)
+		if l.radius != want {
+			t.Errorf("got radius %v, want %v", l.radius, want)
+		}
+		// Ticks are drawn around the circle, so none are left room for
+		// along the edges.
+		if area.x0 != margin || area.y1 != h-1.5*margin {
+			t.Errorf("got chart area %+v, want it to reach the margins", area)
+		}
+	})
+}
@@ -0,0 +1,177 @@
+package ggg
+
+import (
+	"image/color"
+	"math"
+)
+
+// legendEntry describes how one group of data is drawn, for the legend.
+type legendEntry struct {
+	label string
+	color color.Color
+	line  bool
+	point bool
+	fill  bool
+
+	// bar, if not nil, describes a continuous color mapping to draw as a
+	// colorbar, instead of a swatch.
+	bar *colorBar
+}
+
+// legendEntries collects the legend entries of all the plot's layers,
+// merging entries with the same label.
+func (p *Plot) legendEntries(th *Theme) []legendEntry {
+	var entries []legendEntry
+	index := make(map[string]int)
+	for _, l := range p.layers {
+		for _, e := range l.legend(th) {
+			if i, ok := index[e.label]; ok {
+				entries[i].line = entries[i].line || e.line
+				entries[i].point = entries[i].point || e.point
+				entries[i].fill = entries[i].fill || e.fill
+				continue
+			}
+			index[e.label] = len(entries)
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// showLegend reports whether the plot's legend should be drawn on c. Unless
+// it's been asked for, the legend is only drawn on canvases that record
+// points for interactive output.
+func (p *Plot) showLegend(c canvas) bool {
+	if p.opts.legend.shown || p.opts.legend.hidden {
+		return p.opts.legend.shown
+	}
+	_, ok := c.(pointRecorder)
+	return ok
+}
+
+// drawLegend draws a box listing entries in a corner of the chart area,
+// which has its top-left corner at (x, y) and is w by h in size.
+func (p *Plot) drawLegend(c canvas, th *Theme, entries []legendEntry, tf typeface, thickness, x, y, w, h float64) {
+	if len(entries) == 0 || !p.showLegend(c) {
+		return
+	}
+	fh := fontHeight(c, tf)
+	pad := fh / 2
+	var entryWidth float64
+	for _, e := range entries {
+		entryWidth = max(entryWidth, legendEntryWidth(c, tf, e))
+	}
+	bw := 2*pad + entryWidth
+	bh := 2*pad + float64(len(entries))*fh
+
+	// Position the box.
+	bx, by := x+w-pad-bw, y+pad
+	switch p.opts.legend.position {
+	case LegendTopLeft:
+		bx = x + pad
+	case LegendBottomRight:
+		by = y + h - pad - bh
+	case LegendBottomLeft:
+		bx, by = x+pad, y+h-pad-bh
+	}
+
+	beginGroup(c, "legend", "")
+	c.rect(bx, by, bw, bh)
+	bg := color.NRGBAModel.Convert(th.ChartBackgroundColor).(color.NRGBA)
+	bg.A = 0xcc
+	c.setColor(bg)
+	c.fill()
+	c.rect(bx, by, bw, bh)
+	c.setColor(th.GridlineColor)
+	c.setLineWidth(thickness)
+	c.stroke()
+	for i, e := range entries {
+		beginGroup(c, "legend-entry", e.label)
+		cy := by + pad + (float64(i)+0.5)*fh
+		drawLegendEntry(c, th, e, tf, thickness, bx+pad, cy)
+		endGroup(c)
+	}
+	endGroup(c)
+}
+
+// drawLegendRow draws entries in a row centered in the area with its
+// top-left corner at (x, y) that is w wide and one line of tf high.
+func drawLegendRow(c canvas, th *Theme, entries []legendEntry, tf typeface, thickness, x, y, w float64) {
+	fh := fontHeight(c, tf)
+	widths := make([]float64, len(entries))
+	var total float64
+	for i, e := range entries {
+		widths[i] = legendEntryWidth(c, tf, e)
+		total += widths[i]
+	}
+	total += float64(len(entries)-1) * fh
+	ex := x + (w-total)/2
+	beginGroup(c, "legend", "")
+	for i, e := range entries {
+		beginGroup(c, "legend-entry", e.label)
+		drawLegendEntry(c, th, e, tf, thickness, ex, y+fh/2)
+		endGroup(c)
+		ex += widths[i] + fh
+	}
+	endGroup(c)
+}
+
+// colorBarWidth is the width of the gradient in a colorbar, relative to the
+// height of the legend's font.
+const colorBarWidth = 6
+
+// legendEntryWidth returns the width of e when drawn by drawLegendEntry.
+func legendEntryWidth(c canvas, tf typeface, e legendEntry) float64 {
+	fh := fontHeight(c, tf)
+	lw, _ := measureText(c, tf, e.label)
+	if b := e.bar; b != nil {
+		low, _ := measureText(c, tf, b.label(b.lo))
+		hiw, _ := measureText(c, tf, b.label(b.hi))
+		return lw + fh/2 + low + fh/4 + colorBarWidth*fh + fh/4 + hiw
+	}
+	return 2*fh + fh/2 + lw
+}
+
+// drawLegendEntry draws the swatch and label for e, starting at x and
+// vertically centered on cy. Colorbars are drawn as the label followed by
+// the gradient, between the values at either end.
+func drawLegendEntry(c canvas, th *Theme, e legendEntry, tf typeface, thickness, x, cy float64) {
+	fh := fontHeight(c, tf)
+	if b := e.bar; b != nil {
+		c.setColor(th.ForegroundColor)
+		lw, _ := measureText(c, tf, e.label)
+		drawText(c, tf, e.label, x, cy, 0, 0.35)
+		x += lw + fh/2
+		low, _ := measureText(c, tf, b.label(b.lo))
+		drawText(c, tf, b.label(b.lo), x, cy, 0, 0.35)
+		x += low + fh/4
+		const steps = 32
+		w := colorBarWidth * fh
+		for i := range steps {
+			c.setColor(b.scale((float64(i) + 0.5) / steps))
+			c.rect(x+w*float64(i)/steps, cy-fh/3, w/steps+0.5, 2*fh/3)
+			c.fill()
+		}
+		c.setColor(th.ForegroundColor)
+		drawText(c, tf, b.label(b.hi), x+w+fh/4, cy, 0, 0.35)
+		return
+	}
+	swatch := 2 * fh
+	c.setColor(e.color)
+	if e.line {
+		c.moveTo(x, cy)
+		c.lineTo(x+swatch, cy)
+		c.setLineWidth(2 * thickness)
+		c.stroke()
+	}
+	if e.fill {
+		c.rect(x+swatch/2-fh/3, cy-fh/3, 2*fh/3, 2*fh/3)
+		c.fill()
+	}
+	if e.point || !e.line && !e.fill {
+		c.circle(x+swatch/2, cy, math.Max(3*thickness, fh/6))
+		c.fill()
+	}
+	c.setColor(th.ForegroundColor)
+	drawText(c, tf, e.label, x+swatch+fh/2, cy, 0, 0.35)
+}
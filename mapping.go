@@ -1,10 +1,19 @@
 package ggg
 
-import "image/color"
+import (
+	"cmp"
+	"image/color"
+	"math"
+	"reflect"
+	"time"
+)
 
 type Mapping[O comparable] struct {
 	selector func(*Dataset, int) any
 	scale    func(*Dataset, int, *Theme) O
+
+	// bar, if not nil, describes a continuous color mapping.
+	bar *colorBar
 }
 
 func Constant[O comparable](value O) Mapping[O] {
@@ -94,3 +103,117 @@ func Identity[T comparable](col Column[T]) Mapping[T] {
 type Scalar interface {
 	~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~float32 | ~float64
 }
+
+// Value is the set of types that may be positioned along an axis.
+//
+// Times are positioned by the instant they represent, regardless of their
+// location. Ticks along a time axis fall on calendar boundaries in the
+// local time zone, time.Local, and are labeled in it.
+type Value interface {
+	Scalar | time.Time
+}
+
+// toFloat returns the position of v along an axis. Times are positioned
+// by their Unix time in nanoseconds, and durations by their length in
+// nanoseconds.
+func toFloat[T Value](v T) float64 {
+	switch v := any(v).(type) {
+	case time.Time:
+		return float64(v.UnixNano())
+	case time.Duration:
+		return float64(v)
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	case int:
+		return float64(v)
+	case int8:
+		return float64(v)
+	case int16:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint:
+		return float64(v)
+	case uint8:
+		return float64(v)
+	case uint16:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	}
+	// Slow path for named types whose underlying type is a Scalar.
+	r := reflect.ValueOf(v)
+	switch {
+	case r.CanInt():
+		return float64(r.Int())
+	case r.CanUint():
+		return float64(r.Uint())
+	case r.CanFloat():
+		return r.Float()
+	}
+	panic("unreachable")
+}
+
+// compareValues compares a and b in axis order.
+func compareValues[T Value](a, b T) int {
+	if at, ok := any(a).(time.Time); ok {
+		return at.Compare(any(b).(time.Time))
+	}
+	return cmp.Compare(toFloat(a), toFloat(b))
+}
+
+// valueKey returns a key identifying v, for values of any type, such as in
+// a map. Like compareValues, it treats equal instants in different
+// locations, and all NaNs, as the same.
+func valueKey(v any) any {
+	switch v := v.(type) {
+	case time.Time:
+		return timeKey{v.Unix(), v.Nanosecond()}
+	case float64:
+		if math.IsNaN(v) {
+			return nanKey{}
+		}
+	case float32:
+		if math.IsNaN(float64(v)) {
+			return nanKey{}
+		}
+	}
+	return v
+}
+
+type (
+	timeKey struct {
+		sec  int64
+		nsec int
+	}
+	nanKey struct{}
+)
+
+// valueKind describes how values along an axis should be interpreted
+// for the purposes of generating and labeling ticks.
+type valueKind int
+
+const (
+	valueNumber valueKind = iota
+	valueTime
+	valueDuration
+
+	// valueNone is the kind of an axis a layer has no values along.
+	valueNone
+)
+
+func kindOf[T Value]() valueKind {
+	switch reflect.TypeFor[T]() {
+	case reflect.TypeFor[time.Time]():
+		return valueTime
+	case reflect.TypeFor[time.Duration]():
+		return valueDuration
+	}
+	return valueNumber
+}
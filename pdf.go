@@ -0,0 +1,411 @@
+package ggg
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/math/fixed"
+)
+
+// RenderPDF renders the plot as a single-page PDF document of the provided
+// size in points and writes it to w. Subsets of the theme's fonts are
+// embedded in the document.
+func (p *Plot) RenderPDF(w io.Writer, theme string, width, height int) error {
+	th, err := lookupTheme(theme)
+	if err != nil {
+		return err
+	}
+	pc := newPDFCanvas(width, height)
+	if err := p.draw(pc, th, float64(width), float64(height)); err != nil {
+		return err
+	}
+	return pc.finish(w)
+}
+
+// pdfCanvas is a canvas that produces a PDF document.
+type pdfCanvas struct {
+	width, height int
+
+	content bytes.Buffer
+	path    bytes.Buffer
+	state   pdfState
+	stack   []pdfState
+
+	fonts    map[*truetype.Font]*pdfFont
+	fontSeq  []*pdfFont
+	alphas   map[uint8]string
+	alphaSeq []uint8
+	faceCache
+}
+
+// pdfState is the graphics state saved and restored by push and pop.
+type pdfState struct {
+	color     color.Color
+	lineWidth float64
+	lineCap   lineCap
+	lineJoin  lineJoin
+	font      *truetype.Font
+	fontSize  float64
+
+	// emitted is the graphics state as last written to the content stream.
+	emitted struct {
+		stroke, fill [3]float64
+		alpha        uint8
+		lineWidth    float64
+		lineCap      lineCap
+		lineJoin     lineJoin
+	}
+}
+
+func newPDFCanvas(width, height int) *pdfCanvas {
+	pc := &pdfCanvas{
+		width:     width,
+		height:    height,
+		fonts:     make(map[*truetype.Font]*pdfFont),
+		alphas:    make(map[uint8]string),
+		faceCache: make(faceCache),
+	}
+	// Match the PDF defaults, except for line caps and joins, which match gg.
+	pc.state.color = color.Black
+	pc.state.lineWidth = 1
+	pc.state.emitted.alpha = 0xff
+	pc.state.emitted.lineWidth = 1
+	pc.state.emitted.lineCap = lineCapButt
+	pc.state.emitted.lineJoin = -1
+
+	// Flip the coordinate system so the origin is at the top-left.
+	fmt.Fprintf(&pc.content, "1 0 0 -1 0 %d cm\n", height)
+	return pc
+}
+
+func (pc *pdfCanvas) setColor(c color.Color) {
+	pc.state.color = c
+}
+
+func (pc *pdfCanvas) setLineWidth(w float64) {
+	pc.state.lineWidth = w
+}
+
+func (pc *pdfCanvas) setLineCap(lc lineCap) {
+	pc.state.lineCap = lc
+}
+
+func (pc *pdfCanvas) setLineJoin(lj lineJoin) {
+	pc.state.lineJoin = lj
+}
+
+func (pc *pdfCanvas) setFont(f *truetype.Font, size float64) {
+	pc.state.font = f
+	pc.state.fontSize = size
+}
+
+func (pc *pdfCanvas) moveTo(x, y float64) {
+	fmt.Fprintf(&pc.path, "%s %s m\n", pdfNum(x), pdfNum(y))
+}
+
+func (pc *pdfCanvas) lineTo(x, y float64) {
+	fmt.Fprintf(&pc.path, "%s %s l\n", pdfNum(x), pdfNum(y))
+}
+
+func (pc *pdfCanvas) closePath() {
+	pc.path.WriteString("h\n")
+}
+
+func (pc *pdfCanvas) circle(x, y, r float64) {
+	// Approximate the circle with four cubic Bézier curves.
+	const k = 0.5522847498
+	kr := k * r
+	pc.moveTo(x+r, y)
+	fmt.Fprintf(&pc.path, "%s %s %s %s %s %s c\n", pdfNum(x+r), pdfNum(y+kr), pdfNum(x+kr), pdfNum(y+r), pdfNum(x), pdfNum(y+r))
+	fmt.Fprintf(&pc.path, "%s %s %s %s %s %s c\n", pdfNum(x-kr), pdfNum(y+r), pdfNum(x-r), pdfNum(y+kr), pdfNum(x-r), pdfNum(y))
+	fmt.Fprintf(&pc.path, "%s %s %s %s %s %s c\n", pdfNum(x-r), pdfNum(y-kr), pdfNum(x-kr), pdfNum(y-r), pdfNum(x), pdfNum(y-r))
+	fmt.Fprintf(&pc.path, "%s %s %s %s %s %s c\n", pdfNum(x+kr), pdfNum(y-r), pdfNum(x+r), pdfNum(y-kr), pdfNum(x+r), pdfNum(y))
+	pc.closePath()
+}
+
+func (pc *pdfCanvas) rect(x, y, w, h float64) {
+	fmt.Fprintf(&pc.path, "%s %s %s %s re\n", pdfNum(x), pdfNum(y), pdfNum(w), pdfNum(h))
+}
+
+func (pc *pdfCanvas) stroke() {
+	if pc.path.Len() == 0 {
+		return
+	}
+	pc.emitStroke()
+	pc.content.Write(pc.path.Bytes())
+	pc.content.WriteString("S\n")
+	pc.path.Reset()
+}
+
+func (pc *pdfCanvas) fill() {
+	if pc.path.Len() == 0 {
+		return
+	}
+	pc.emitFill()
+	pc.content.Write(pc.path.Bytes())
+	pc.content.WriteString("f\n")
+	pc.path.Reset()
+}
+
+func (pc *pdfCanvas) drawString(s string, x, y float64) {
+	if pc.state.font == nil || s == "" {
+		return
+	}
+	pf, ok := pc.fonts[pc.state.font]
+	if !ok {
+		pf = newPDFFont(fmt.Sprintf("F%d", len(pc.fontSeq)), pc.state.font)
+		pc.fonts[pc.state.font] = pf
+		pc.fontSeq = append(pc.fontSeq, pf)
+	}
+	pc.emitFill()
+	// Flip the text matrix back, since the page is flipped.
+	fmt.Fprintf(&pc.content, "BT /%s %s Tf 1 0 0 -1 %s %s Tm %s TJ ET\n",
+		pf.name, pdfNum(pc.state.fontSize), pdfNum(x), pdfNum(y), pf.encode(s))
+}
+
+func (pc *pdfCanvas) push() {
+	pc.stack = append(pc.stack, pc.state)
+	pc.content.WriteString("q\n")
+}
+
+func (pc *pdfCanvas) pop() {
+	pc.state = pc.stack[len(pc.stack)-1]
+	pc.stack = pc.stack[:len(pc.stack)-1]
+	pc.content.WriteString("Q\n")
+}
+
+func (pc *pdfCanvas) translate(x, y float64) {
+	fmt.Fprintf(&pc.content, "1 0 0 1 %s %s cm\n", pdfNum(x), pdfNum(y))
+}
+
+func (pc *pdfCanvas) rotate(angle float64) {
+	s, c := math.Sincos(angle)
+	fmt.Fprintf(&pc.content, "%s %s %s %s 0 0 cm\n", pdfNum(c), pdfNum(s), pdfNum(-s), pdfNum(c))
+}
+
+// emitStroke writes out any changes to the stroking state.
+func (pc *pdfCanvas) emitStroke() {
+	st := &pc.state
+	rgb, a := pdfColor(st.color)
+	pc.emitAlpha(a)
+	if rgb != st.emitted.stroke {
+		fmt.Fprintf(&pc.content, "%s %s %s RG\n", pdfNum(rgb[0]), pdfNum(rgb[1]), pdfNum(rgb[2]))
+		st.emitted.stroke = rgb
+	}
+	if st.lineWidth != st.emitted.lineWidth {
+		fmt.Fprintf(&pc.content, "%s w\n", pdfNum(st.lineWidth))
+		st.emitted.lineWidth = st.lineWidth
+	}
+	if st.lineCap != st.emitted.lineCap {
+		switch st.lineCap {
+		case lineCapButt:
+			pc.content.WriteString("0 J\n")
+		case lineCapRound:
+			pc.content.WriteString("1 J\n")
+		case lineCapSquare:
+			pc.content.WriteString("2 J\n")
+		}
+		st.emitted.lineCap = st.lineCap
+	}
+	if st.lineJoin != st.emitted.lineJoin {
+		switch st.lineJoin {
+		case lineJoinRound:
+			pc.content.WriteString("1 j\n")
+		case lineJoinBevel:
+			pc.content.WriteString("2 j\n")
+		}
+		st.emitted.lineJoin = st.lineJoin
+	}
+}
+
+// emitFill writes out any changes to the filling state.
+func (pc *pdfCanvas) emitFill() {
+	st := &pc.state
+	rgb, a := pdfColor(st.color)
+	pc.emitAlpha(a)
+	if rgb != st.emitted.fill {
+		fmt.Fprintf(&pc.content, "%s %s %s rg\n", pdfNum(rgb[0]), pdfNum(rgb[1]), pdfNum(rgb[2]))
+		st.emitted.fill = rgb
+	}
+}
+
+func (pc *pdfCanvas) emitAlpha(a uint8) {
+	if a == pc.state.emitted.alpha {
+		return
+	}
+	name, ok := pc.alphas[a]
+	if !ok {
+		name = fmt.Sprintf("GS%d", len(pc.alphas))
+		pc.alphas[a] = name
+		pc.alphaSeq = append(pc.alphaSeq, a)
+	}
+	fmt.Fprintf(&pc.content, "/%s gs\n", name)
+	pc.state.emitted.alpha = a
+}
+
+// finish writes out the complete document to w.
+func (pc *pdfCanvas) finish(w io.Writer) error {
+	pw := &pdfWriter{w: bufio.NewWriter(w)}
+	pw.printf("%%PDF-1.7\n%%\xe2\xe3\xcf\xd3\n")
+
+	// Reserve object numbers for the fixed objects.
+	catalog, pages, page := pw.alloc(), pw.alloc(), pw.alloc()
+
+	var fontRefs bytes.Buffer
+	for _, pf := range pc.fontSeq {
+		ref, err := pw.writeFont(pf)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&fontRefs, "/%s %d 0 R ", pf.name, ref)
+	}
+	var gsRefs bytes.Buffer
+	for _, a := range pc.alphaSeq {
+		fmt.Fprintf(&gsRefs, "/%s << /Type /ExtGState /CA %s /ca %s >> ", pc.alphas[a], pdfNum(float64(a)/0xff), pdfNum(float64(a)/0xff))
+	}
+	contents := pw.writeStream("", pc.content.Bytes(), true)
+
+	pw.begin(catalog)
+	pw.printf("<< /Type /Catalog /Pages %d 0 R >>\n", pages)
+	pw.end()
+	pw.begin(pages)
+	pw.printf("<< /Type /Pages /Kids [%d 0 R] /Count 1 >>\n", page)
+	pw.end()
+	pw.begin(page)
+	pw.printf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %d %d] /Contents %d 0 R\n", pages, pc.width, pc.height, contents)
+	pw.printf("/Resources << /Font << %s>> /ExtGState << %s>> >> >>\n", fontRefs.String(), gsRefs.String())
+	pw.end()
+	return pw.finish(catalog)
+}
+
+// pdfWriter writes out the objects of a PDF document and tracks their
+// offsets for the cross-reference table.
+type pdfWriter struct {
+	w       *bufio.Writer
+	n       int
+	offsets []int
+	err     error
+}
+
+func (pw *pdfWriter) printf(format string, args ...any) {
+	if pw.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(pw.w, format, args...)
+	pw.n += n
+	pw.err = err
+}
+
+func (pw *pdfWriter) write(b []byte) {
+	if pw.err != nil {
+		return
+	}
+	n, err := pw.w.Write(b)
+	pw.n += n
+	pw.err = err
+}
+
+// alloc reserves an object number.
+func (pw *pdfWriter) alloc() int {
+	pw.offsets = append(pw.offsets, -1)
+	return len(pw.offsets)
+}
+
+func (pw *pdfWriter) begin(obj int) {
+	pw.offsets[obj-1] = pw.n
+	pw.printf("%d 0 obj\n", obj)
+}
+
+func (pw *pdfWriter) end() {
+	pw.printf("endobj\n")
+}
+
+// writeStream writes a stream object with the extra dictionary entries in
+// dict, and returns its object number.
+func (pw *pdfWriter) writeStream(dict string, data []byte, compress bool) int {
+	if compress {
+		var b bytes.Buffer
+		zw := zlib.NewWriter(&b)
+		zw.Write(data)
+		zw.Close()
+		data = b.Bytes()
+		dict += " /Filter /FlateDecode"
+	}
+	obj := pw.alloc()
+	pw.begin(obj)
+	pw.printf("<<%s /Length %d >>\nstream\n", dict, len(data))
+	pw.write(data)
+	pw.printf("\nendstream\n")
+	pw.end()
+	return obj
+}
+
+// writeFont writes out an embedded subset of a font as a composite font,
+// and returns the object number of the font dictionary.
+func (pw *pdfWriter) writeFont(pf *pdfFont) (int, error) {
+	program, err := pf.subset()
+	if err != nil {
+		return 0, err
+	}
+	file := pw.writeStream(fmt.Sprintf(" /Length1 %d", len(program)), program, true)
+	toUnicode := pw.writeStream("", pf.toUnicode(), true)
+
+	scale := 1000 / float64(pf.f.FUnitsPerEm())
+	b := pf.f.Bounds(fixed.Int26_6(pf.f.FUnitsPerEm()))
+	bbox := fmt.Sprintf("[%d %d %d %d]",
+		int(float64(b.Min.X)*scale), int(float64(b.Min.Y)*scale),
+		int(float64(b.Max.X)*scale), int(float64(b.Max.Y)*scale))
+	base := pf.baseFont()
+
+	descriptor := pw.alloc()
+	pw.begin(descriptor)
+	pw.printf("<< /Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox %s /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>\n",
+		base, bbox, int(float64(b.Max.Y)*scale), int(float64(b.Min.Y)*scale), int(float64(b.Max.Y)*scale), file)
+	pw.end()
+
+	cidFont := pw.alloc()
+	pw.begin(cidFont)
+	pw.printf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /CIDToGIDMap /Identity /W %s >>\n",
+		base, descriptor, pf.widths())
+	pw.end()
+
+	font := pw.alloc()
+	pw.begin(font)
+	pw.printf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>\n",
+		base, cidFont, toUnicode)
+	pw.end()
+	return font, pw.err
+}
+
+// finish writes the cross-reference table and trailer, and flushes.
+func (pw *pdfWriter) finish(root int) error {
+	xref := pw.n
+	pw.printf("xref\n0 %d\n0000000000 65535 f \n", len(pw.offsets)+1)
+	for _, off := range pw.offsets {
+		pw.printf("%010d 00000 n \n", off)
+	}
+	pw.printf("trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(pw.offsets)+1, root, xref)
+	if pw.err != nil {
+		return pw.err
+	}
+	return pw.w.Flush()
+}
+
+// pdfNum formats a number compactly.
+func pdfNum(x float64) string {
+	return strconv.FormatFloat(math.Round(x*1000)/1000, 'f', -1, 64)
+}
+
+// pdfColor returns the un-premultiplied components of c in [0, 1], and its
+// alpha.
+func pdfColor(c color.Color) ([3]float64, uint8) {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return [3]float64{float64(n.R) / 0xff, float64(n.G) / 0xff, float64(n.B) / 0xff}, n.A
+}
@@ -0,0 +1,232 @@
+package ggg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image/color"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/mknyszek/ggg/third_party/roboto"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+func parseRoboto(t *testing.T) *truetype.Font {
+	t.Helper()
+	f, err := truetype.Parse(roboto.RegularTTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestPDFFontSubset(t *testing.T) {
+	f := parseRoboto(t)
+	upe := fixed.Int26_6(f.FUnitsPerEm())
+	type test struct {
+		name    string
+		strings []string
+		unused  string
+	}
+	for _, ts := range []test{
+		{
+			name:    "Empty",
+			strings: nil,
+			unused:  "a",
+		},
+		{
+			name:    "Word",
+			strings: []string{"Hello"},
+			unused:  "Z",
+		},
+		{
+			name:    "Symbols",
+			strings: []string{"−1.5µs", "10³", "x–y"},
+			unused:  "q",
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			pf := newPDFFont("F0", f)
+			var runes []rune
+			for _, s := range ts.strings {
+				pf.encode(s)
+				runes = append(runes, []rune(s)...)
+			}
+			program, err := pf.subset()
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The subset has no cmap, since PDF maps characters to glyphs
+			// itself, so add one to parse it.
+			sub, err := truetype.Parse(withCmap(t, program, f, runes))
+			if err != nil {
+				t.Fatalf("parsing subset: %v", err)
+			}
+
+			// Used glyphs keep their index, width and outline.
+			var want, got truetype.GlyphBuf
+			for _, r := range runes {
+				idx := f.Index(r)
+				if a, b := f.HMetric(upe, idx).AdvanceWidth, sub.HMetric(upe, idx).AdvanceWidth; a != b {
+					t.Errorf("glyph for %q: got width %v, want %v", r, b, a)
+				}
+				if err := want.Load(f, upe, idx, font.HintingNone); err != nil {
+					t.Fatal(err)
+				}
+				if err := got.Load(sub, upe, idx, font.HintingNone); err != nil {
+					t.Fatalf("loading glyph for %q from subset: %v", r, err)
+				}
+				// Only the on-curve flag survives; the others describe how
+				// the points were compressed.
+				same := func(a, b truetype.Point) bool {
+					return a.X == b.X && a.Y == b.Y && a.Flags&1 == b.Flags&1
+				}
+				if !slices.EqualFunc(got.Points, want.Points, same) || !slices.Equal(got.Ends, want.Ends) {
+					t.Errorf("glyph for %q: outline differs in subset", r)
+				}
+				// The left side bearing is where the outline starts, even
+				// for glyphs like Roboto's en dash, whose outline is loaded
+				// offset from the font's bearing.
+				if lsb := sub.HMetric(upe, idx).LeftSideBearing; len(got.Ends) != 0 && lsb != got.Bounds.Min.X {
+					t.Errorf("glyph for %q: got left side bearing %v, want %v", r, lsb, got.Bounds.Min.X)
+				}
+			}
+
+			// Others are left empty.
+			if idx := f.Index([]rune(ts.unused)[0]); int(idx) < len(pf.glyphs()) && !slices.Contains(pf.glyphs(), idx) {
+				if err := got.Load(sub, upe, idx, font.HintingNone); err != nil {
+					t.Fatal(err)
+				}
+				if len(got.Points) != 0 {
+					t.Errorf("unused glyph for %q has %d points in subset", ts.unused, len(got.Points))
+				}
+			}
+
+			// Text may be copied back out.
+			cmap := string(pf.toUnicode())
+			for _, r := range runes {
+				entry := fmt.Sprintf("<%04x> <%04x>", uint16(f.Index(r)), r)
+				if !strings.Contains(cmap, entry) {
+					t.Errorf("ToUnicode CMap lacks %s for %q", entry, r)
+				}
+			}
+		})
+	}
+}
+
+// withCmap returns the font program with a cmap table added, mapping runes
+// to their glyphs in f, after checking the checksums of its tables.
+func withCmap(t *testing.T, program []byte, f *truetype.Font, runes []rune) []byte {
+	t.Helper()
+	if sum := sfntChecksum(program); sum != 0xb1b0afba {
+		t.Errorf("font checksum is %#x, want 0xb1b0afba", sum)
+	}
+	n := int(binary.BigEndian.Uint16(program[4:]))
+	var tables []sfntTable
+	for i := range n {
+		entry := program[12+16*i:]
+		tag := string(entry[:4])
+		sum := binary.BigEndian.Uint32(entry[4:])
+		off, length := binary.BigEndian.Uint32(entry[8:]), binary.BigEndian.Uint32(entry[12:])
+		data := program[off : off+length]
+		if tag == "head" {
+			// The checksum adjustment is left out of the table's checksum.
+			data = slices.Clone(data)
+			binary.BigEndian.PutUint32(data[8:], 0)
+		}
+		if got := sfntChecksum(data); got != sum {
+			t.Errorf("table %s has checksum %#x, want %#x", tag, got, sum)
+		}
+		tables = append(tables, sfntTable{tag, data})
+	}
+
+	// A format 12 subtable, with a group for each rune.
+	runes = slices.Clone(runes)
+	slices.Sort(runes)
+	runes = slices.Compact(runes)
+	var cmap bytes.Buffer
+	binary.Write(&cmap, binary.BigEndian, struct {
+		Version, NumTables, Platform, Encoding uint16
+		Offset                                 uint32
+		Format, Reserved                       uint16
+		Length, Language, NumGroups            uint32
+	}{0, 1, 3, 10, 12, 12, 0, uint32(16 + 12*len(runes)), 0, uint32(len(runes))})
+	for _, r := range runes {
+		binary.Write(&cmap, binary.BigEndian, [3]uint32{uint32(r), uint32(r), uint32(f.Index(r))})
+	}
+	return writeSFNT(append(tables, sfntTable{"cmap", cmap.Bytes()}))
+}
+
+func TestPDFFontEncode(t *testing.T) {
+	f := parseRoboto(t)
+	pf := newPDFFont("F0", f)
+	if got, want := pf.encode("ll"), fmt.Sprintf("[<%04x%04x>]", f.Index('l'), f.Index('l')); got != want {
+		t.Errorf("encode(%q) = %q, want %q", "ll", got, want)
+	}
+	pf.encode("Hi")
+	if gs := pf.glyphs(); len(gs) != 4 || gs[0] != 0 || !slices.IsSorted(gs) {
+		t.Errorf("got glyphs %v, want the missing glyph and three others, in order", gs)
+	}
+	tag := pf.tag()
+	if !regexp.MustCompile(`^[A-Z]{6}$`).MatchString(tag) {
+		t.Errorf("got subset tag %q, want six capital letters", tag)
+	}
+	if got := pf.baseFont(); got != tag+"+Roboto-Regular" {
+		t.Errorf("got base font %q, want %q", got, tag+"+Roboto-Regular")
+	}
+}
+
+func TestPDFWriter(t *testing.T) {
+	pc := newPDFCanvas(200, 100)
+	pc.setFont(parseRoboto(t), 12)
+	pc.setColor(color.NRGBA{0xff, 0, 0, 0x80})
+	pc.rect(10, 10, 50, 50)
+	pc.fill()
+	pc.drawString("Hi", 20, 80)
+	var buf bytes.Buffer
+	if err := pc.finish(&buf); err != nil {
+		t.Fatal(err)
+	}
+	doc := buf.Bytes()
+	if !bytes.HasPrefix(doc, []byte("%PDF-1.7\n")) || !bytes.HasSuffix(doc, []byte("%%EOF\n")) {
+		t.Fatalf("document isn't delimited as a PDF")
+	}
+
+	// The trailer points to the cross-reference table, which points to
+	// each object.
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(doc)
+	if m == nil {
+		t.Fatal("no startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(doc[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d doesn't point to the cross-reference table", xref)
+	}
+	lines := strings.Split(string(doc[xref:]), "\n")
+	var n int
+	fmt.Sscanf(lines[1], "0 %d", &n)
+	for obj := 1; obj < n; obj++ {
+		var off int
+		fmt.Sscanf(lines[2+obj], "%d", &off)
+		if prefix := fmt.Sprintf("%d 0 obj\n", obj); !bytes.HasPrefix(doc[off:], []byte(prefix)) {
+			t.Errorf("object %d isn't at offset %d", obj, off)
+		}
+	}
+	for _, want := range []string{
+		"/Type /Catalog",
+		"/MediaBox [0 0 200 100]",
+		"/Subtype /CIDFontType2",
+		"/ToUnicode",
+		"/ExtGState << /GS0 << /Type /ExtGState /CA 0.502 /ca 0.502 >>",
+	} {
+		if !bytes.Contains(doc, []byte(want)) {
+			t.Errorf("document lacks %q", want)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package ggg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"slices"
+	"sort"
+	"unicode/utf16"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// pdfFont tracks the glyphs used from a font in a PDF document, so that a
+// subset of the font may be embedded.
+type pdfFont struct {
+	name  string
+	f     *truetype.Font
+	runes map[truetype.Index]rune
+}
+
+func newPDFFont(name string, f *truetype.Font) *pdfFont {
+	return &pdfFont{name: name, f: f, runes: make(map[truetype.Index]rune)}
+}
+
+// encode returns a PDF text-showing array for s, including kerning
+// adjustments, and marks the glyphs as used.
+func (pf *pdfFont) encode(s string) string {
+	var b bytes.Buffer
+	upe := fixed.Int26_6(pf.f.FUnitsPerEm())
+	b.WriteString("[<")
+	prev := truetype.Index(0)
+	for i, r := range []rune(s) {
+		idx := pf.f.Index(r)
+		if i > 0 {
+			if k := pf.f.Kern(upe, prev, idx); k != 0 {
+				// Adjustments are in thousandths of an em, and positive
+				// values move the next glyph to the left.
+				fmt.Fprintf(&b, "> %d <", -int(k)*1000/int(upe))
+			}
+		}
+		if _, ok := pf.runes[idx]; !ok {
+			pf.runes[idx] = r
+		}
+		fmt.Fprintf(&b, "%04x", uint16(idx))
+		prev = idx
+	}
+	b.WriteString(">]")
+	return b.String()
+}
+
+// glyphs returns the used glyph indices in increasing order. Glyph 0, the
+// missing glyph, is always included.
+func (pf *pdfFont) glyphs() []truetype.Index {
+	gs := []truetype.Index{0}
+	for idx := range pf.runes {
+		if idx != 0 {
+			gs = append(gs, idx)
+		}
+	}
+	slices.Sort(gs)
+	return gs
+}
+
+// tag returns the six-letter tag identifying this subset.
+func (pf *pdfFont) tag() string {
+	h := fnv.New64a()
+	io.WriteString(h, pf.f.Name(truetype.NameIDPostscriptName))
+	for _, g := range pf.glyphs() {
+		binary.Write(h, binary.BigEndian, uint16(g))
+	}
+	v := h.Sum64()
+	var t [6]byte
+	for i := range t {
+		t[i] = 'A' + byte(v%26)
+		v /= 26
+	}
+	return string(t[:])
+}
+
+// baseFont returns the PostScript name of the subset.
+func (pf *pdfFont) baseFont() string {
+	name := pf.f.Name(truetype.NameIDPostscriptName)
+	if name == "" {
+		name = "Font"
+	}
+	return pf.tag() + "+" + name
+}
+
+// toUnicode returns a CMap mapping used glyphs back to Unicode, which
+// allows text to be searched and copied.
+func (pf *pdfFont) toUnicode() []byte {
+	var b bytes.Buffer
+	b.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n")
+	b.WriteString("/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n")
+	b.WriteString("/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n")
+	b.WriteString("1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	var gs []truetype.Index
+	for idx := range pf.runes {
+		gs = append(gs, idx)
+	}
+	slices.Sort(gs)
+	for len(gs) > 0 {
+		n := min(len(gs), 100)
+		fmt.Fprintf(&b, "%d beginbfchar\n", n)
+		for _, idx := range gs[:n] {
+			fmt.Fprintf(&b, "<%04x> <", uint16(idx))
+			for _, u := range utf16.Encode([]rune{pf.runes[idx]}) {
+				fmt.Fprintf(&b, "%04x", u)
+			}
+			b.WriteString(">\n")
+		}
+		b.WriteString("endbfchar\n")
+		gs = gs[n:]
+	}
+	b.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return b.Bytes()
+}
+
+// widths returns the PDF W array for the used glyphs, in thousandths of an em.
+func (pf *pdfFont) widths() string {
+	var b bytes.Buffer
+	upe := fixed.Int26_6(pf.f.FUnitsPerEm())
+	b.WriteString("[")
+	for _, idx := range pf.glyphs() {
+		hm := pf.f.HMetric(upe, idx)
+		fmt.Fprintf(&b, "%d [%d] ", idx, int(hm.AdvanceWidth)*1000/int(upe))
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+// subset produces a TrueType font program containing only the used glyphs.
+// Glyph indices are preserved, and unused glyphs are left empty, so that
+// the subset may be used with an identity CID-to-glyph mapping. Glyph
+// outlines are rebuilt from the parsed font, so composite glyphs are
+// flattened and hinting instructions are dropped.
+func (pf *pdfFont) subset() ([]byte, error) {
+	f := pf.f
+	upe := fixed.Int26_6(f.FUnitsPerEm())
+	used := pf.glyphs()
+	numGlyphs := int(used[len(used)-1]) + 1
+
+	var glyf, hmtx bytes.Buffer
+	loca := make([]uint32, 0, numGlyphs+1)
+	var maxPoints, maxContours int
+	var advanceMax uint16
+	var gb truetype.GlyphBuf
+	next := 0
+	for i := range numGlyphs {
+		idx := truetype.Index(i)
+		loca = append(loca, uint32(glyf.Len()))
+		if next >= len(used) || used[next] != idx {
+			binary.Write(&hmtx, binary.BigEndian, [2]uint16{0, 0})
+			continue
+		}
+		next++
+		hm := f.HMetric(upe, idx)
+		advanceMax = max(advanceMax, uint16(hm.AdvanceWidth))
+		if err := gb.Load(f, upe, idx, font.HintingNone); err != nil {
+			return nil, err
+		}
+		// The loaded points are already placed relative to the glyph's
+		// origin, so the left side bearing is where they start. It may
+		// differ from the font's, such as for composite glyphs.
+		lsb := hm.LeftSideBearing
+		if len(gb.Ends) != 0 {
+			lsb = gb.Bounds.Min.X
+		}
+		binary.Write(&hmtx, binary.BigEndian, [2]uint16{uint16(hm.AdvanceWidth), uint16(int16(lsb))})
+		if len(gb.Ends) == 0 {
+			continue
+		}
+		maxPoints = max(maxPoints, len(gb.Points))
+		maxContours = max(maxContours, len(gb.Ends))
+		writeSimpleGlyph(&glyf, &gb)
+		for glyf.Len()%4 != 0 {
+			glyf.WriteByte(0)
+		}
+	}
+	loca = append(loca, uint32(glyf.Len()))
+
+	bounds := f.Bounds(upe)
+	var head, hhea, maxp, locaBuf bytes.Buffer
+	binary.Write(&head, binary.BigEndian, struct {
+		Version, Revision, ChecksumAdjustment, Magic uint32
+		Flags, UnitsPerEm                            uint16
+		Created, Modified                            int64
+		XMin, YMin, XMax, YMax                       int16
+		MacStyle, LowestRecPPEM                      uint16
+		DirectionHint, IndexToLocFormat, DataFormat  int16
+	}{
+		Version:          0x00010000,
+		Revision:         0x00010000,
+		Magic:            0x5f0f3cf5,
+		Flags:            0x000b,
+		UnitsPerEm:       uint16(upe),
+		XMin:             int16(bounds.Min.X),
+		YMin:             int16(bounds.Min.Y),
+		XMax:             int16(bounds.Max.X),
+		YMax:             int16(bounds.Max.Y),
+		LowestRecPPEM:    8,
+		DirectionHint:    2,
+		IndexToLocFormat: 1,
+	})
+	binary.Write(&hhea, binary.BigEndian, struct {
+		Version                                 uint32
+		Ascender, Descender, LineGap            int16
+		AdvanceWidthMax                         uint16
+		MinLSB, MinRSB, XMaxExtent              int16
+		CaretSlopeRise, CaretSlopeRun, CaretOff int16
+		Reserved                                [4]int16
+		MetricDataFormat                        int16
+		NumberOfHMetrics                        uint16
+	}{
+		Version:          0x00010000,
+		Ascender:         int16(bounds.Max.Y),
+		Descender:        int16(bounds.Min.Y),
+		AdvanceWidthMax:  advanceMax,
+		MinLSB:           int16(bounds.Min.X),
+		XMaxExtent:       int16(bounds.Max.X),
+		CaretSlopeRise:   1,
+		NumberOfHMetrics: uint16(numGlyphs),
+	})
+	binary.Write(&maxp, binary.BigEndian, struct {
+		Version                                  uint32
+		NumGlyphs, MaxPoints, MaxContours        uint16
+		MaxCompositePoints, MaxCompositeContours uint16
+		MaxZones, MaxTwilightPoints, MaxStorage  uint16
+		MaxFunctionDefs, MaxInstructionDefs      uint16
+		MaxStackElements, MaxSizeOfInstructions  uint16
+		MaxComponentElements, MaxComponentDepth  uint16
+	}{
+		Version:     0x00010000,
+		NumGlyphs:   uint16(numGlyphs),
+		MaxPoints:   uint16(maxPoints),
+		MaxContours: uint16(maxContours),
+		MaxZones:    2,
+	})
+	binary.Write(&locaBuf, binary.BigEndian, loca)
+
+	return writeSFNT([]sfntTable{
+		{"glyf", glyf.Bytes()},
+		{"head", head.Bytes()},
+		{"hhea", hhea.Bytes()},
+		{"hmtx", hmtx.Bytes()},
+		{"loca", locaBuf.Bytes()},
+		{"maxp", maxp.Bytes()},
+	}), nil
+}
+
+// writeSimpleGlyph writes the glyph loaded into gb as a simple glyph
+// description, without instructions or compression of coordinates.
+func writeSimpleGlyph(w *bytes.Buffer, gb *truetype.GlyphBuf) {
+	b := gb.Bounds
+	binary.Write(w, binary.BigEndian, [5]int16{
+		int16(len(gb.Ends)),
+		int16(b.Min.X), int16(b.Min.Y), int16(b.Max.X), int16(b.Max.Y),
+	})
+	for _, e := range gb.Ends {
+		binary.Write(w, binary.BigEndian, uint16(e-1))
+	}
+	binary.Write(w, binary.BigEndian, uint16(0))
+	for _, p := range gb.Points {
+		w.WriteByte(byte(p.Flags & 1))
+	}
+	var last int16
+	for _, p := range gb.Points {
+		binary.Write(w, binary.BigEndian, int16(p.X)-last)
+		last = int16(p.X)
+	}
+	last = 0
+	for _, p := range gb.Points {
+		binary.Write(w, binary.BigEndian, int16(p.Y)-last)
+		last = int16(p.Y)
+	}
+}
+
+type sfntTable struct {
+	tag  string
+	data []byte
+}
+
+// writeSFNT assembles tables, which must be sorted by tag, into a font file.
+func writeSFNT(tables []sfntTable) []byte {
+	sort.Slice(tables, func(i, j int) bool { return tables[i].tag < tables[j].tag })
+	n := len(tables)
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= n {
+		entrySelector++
+	}
+	searchRange := (1 << entrySelector) * 16
+
+	var b bytes.Buffer
+	binary.Write(&b, binary.BigEndian, struct {
+		Version                                        uint32
+		NumTables, SearchRange, EntrySelector, RangeSh uint16
+	}{0x00010000, uint16(n), uint16(searchRange), uint16(entrySelector), uint16(n*16 - searchRange)})
+	offset := 12 + 16*n
+	headOffset := 0
+	for _, t := range tables {
+		if t.tag == "head" {
+			headOffset = offset
+		}
+		b.WriteString(t.tag)
+		binary.Write(&b, binary.BigEndian, [3]uint32{sfntChecksum(t.data), uint32(offset), uint32(len(t.data))})
+		offset += (len(t.data) + 3) &^ 3
+	}
+	for _, t := range tables {
+		b.Write(t.data)
+		for b.Len()%4 != 0 {
+			b.WriteByte(0)
+		}
+	}
+	font := b.Bytes()
+	if headOffset != 0 {
+		binary.BigEndian.PutUint32(font[headOffset+8:], 0xb1b0afba-sfntChecksum(font))
+	}
+	return font
+}
+
+func sfntChecksum(data []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(data); i += 4 {
+		var v [4]byte
+		copy(v[:], data[i:])
+		sum += binary.BigEndian.Uint32(v[:])
+	}
+	return sum
+}
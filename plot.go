@@ -1,19 +1,64 @@
 package ggg
 
+import "math"
+
 type Plot struct {
-	layers []AnyLayer
+	layers []plotLayer
 	opts   presentOpts
 }
 
+// plotLayer is a layer along with how it's placed in the plot.
+type plotLayer struct {
+	AnyLayer
+	axis AxisID
+}
+
 func NewPlot() *Plot {
 	return &Plot{}
 }
 
-func (p *Plot) Layer(l AnyLayer) *Plot {
-	p.layers = append(p.layers, l)
+func (p *Plot) Layer(l AnyLayer, opts ...LayerOption) *Plot {
+	pl := plotLayer{AnyLayer: l}
+	for _, opt := range opts {
+		opt(&pl)
+	}
+	p.layers = append(p.layers, pl)
 	return p
 }
 
+// LayerOption configures how a layer is placed in a plot.
+type LayerOption func(*plotLayer)
+
+// AxisID identifies a Y axis.
+type AxisID int
+
+const (
+	// Y1 is the primary Y axis, on the left of the plot.
+	Y1 AxisID = iota
+
+	// Y2 is the secondary Y axis, on the right of the plot, which is
+	// scaled independently of Y1.
+	Y2
+)
+
+// OnAxis places the layer on the provided Y axis. By default, layers are
+// placed on Y1.
+func OnAxis(id AxisID) LayerOption {
+	return func(l *plotLayer) {
+		l.axis = id
+	}
+}
+
+// usesY2 reports whether any layer is placed on Y2.
+func (p *Plot) usesY2() bool {
+	for _, l := range p.layers {
+		if l.axis == Y2 {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *Plot) Presentation(opts ...PresentationOption) *Plot {
 	for _, opt := range opts {
 		opt(&p.opts)
@@ -22,9 +67,22 @@ func (p *Plot) Presentation(opts ...PresentationOption) *Plot {
 }
 
 type presentOpts struct {
-	title  string
-	x, y   axis
-	legend legend
+	title    string
+	subtitle string
+	caption  string
+	x, y     axis
+	y2       axis
+	legend   legend
+
+	// frame labels the frame of an animation, and frames are the labels
+	// of all its frames, the widest of which is left room for.
+	frame  string
+	frames []string
+
+	coord coordKind
+
+	// textScale scales the size of all the plot's text, if not zero.
+	textScale float64
 }
 
 type axis struct {
@@ -32,13 +90,24 @@ type axis struct {
 	min, max         float64
 	userMin, userMax float64
 	userLimits       bool
-	logBase          int
+	transform        *axisTransform
+	reversed         bool
+	breaks           [][2]float64
+	locator          TickLocator
 	customTicks      []float64
+	noTicks          bool
+	tickCount        int
+	minor            bool
+	labelAngle       float64
+	titleScale       float64
+	labelScale       float64
+	kind             valueKind
+	format           Formatter
 }
 
 type legend struct {
-	visible   bool
-	alignment int
+	shown, hidden bool
+	position      LegendPosition
 }
 
 type PresentationOption func(*presentOpts)
@@ -49,6 +118,30 @@ func Title(title string) PresentationOption {
 	}
 }
 
+// Subtitle sets a line of text shown below the plot's title.
+func Subtitle(subtitle string) PresentationOption {
+	return func(opts *presentOpts) {
+		opts.subtitle = subtitle
+	}
+}
+
+// Caption sets a note shown in the bottom-right corner of the plot, like
+// the source of its data.
+func Caption(caption string) PresentationOption {
+	return func(opts *presentOpts) {
+		opts.caption = caption
+	}
+}
+
+// TextScale scales the size of all the plot's text by f. By default, text
+// is sized relative to the height of the plot. It has no effect on text
+// renderings.
+func TextScale(f float64) PresentationOption {
+	return func(opts *presentOpts) {
+		opts.textScale = f
+	}
+}
+
 func XAxis(title string, aOpts ...AxisOption) PresentationOption {
 	return func(opts *presentOpts) {
 		opts.x.title = title
@@ -67,6 +160,44 @@ func YAxis(title string, aOpts ...AxisOption) PresentationOption {
 	}
 }
 
+// Y2Axis configures the secondary Y axis, on the right of the plot, which
+// is only drawn if a layer is placed on it with OnAxis(Y2).
+func Y2Axis(title string, aOpts ...AxisOption) PresentationOption {
+	return func(opts *presentOpts) {
+		opts.y2.title = title
+		for _, aOpt := range aOpts {
+			aOpt(&opts.y2)
+		}
+	}
+}
+
+// LegendPosition is a corner of the chart area in which to place the legend.
+type LegendPosition int
+
+const (
+	LegendTopRight LegendPosition = iota
+	LegendTopLeft
+	LegendBottomRight
+	LegendBottomLeft
+)
+
+// Legend shows a legend in the provided corner whenever a layer maps a
+// column to color. By default, a legend is shown in the top-right corner
+// only in HTML output, where clicking its entries toggles their series.
+func Legend(pos LegendPosition) PresentationOption {
+	return func(opts *presentOpts) {
+		opts.legend.shown, opts.legend.hidden = true, false
+		opts.legend.position = pos
+	}
+}
+
+// NoLegend hides the legend.
+func NoLegend() PresentationOption {
+	return func(opts *presentOpts) {
+		opts.legend.shown, opts.legend.hidden = false, true
+	}
+}
+
 type AxisOption func(*axis)
 
 func Limits(min, max float64) AxisOption {
@@ -76,14 +207,91 @@ func Limits(min, max float64) AxisOption {
 	}
 }
 
-func LogScale(base int) AxisOption {
+// Ticks places the axis's ticks at the provided positions. With no
+// positions, the axis has the default ticks.
+func Ticks(ticks ...float64) AxisOption {
 	return func(opts *axis) {
-		opts.logBase = base
+		opts.customTicks = ticks
 	}
 }
 
-func Ticks(ticks ...float64) AxisOption {
+// NoTicks removes the axis's ticks, along with their labels and gridlines.
+func NoTicks() AxisOption {
 	return func(opts *axis) {
-		opts.customTicks = ticks
+		opts.noTicks = true
+	}
+}
+
+// TickCount places roughly n ticks along the axis, rather than the default
+// of around 5. It has no effect on ticks placed by Ticks, Categories or a
+// Locator.
+func TickCount(n int) AxisOption {
+	return func(opts *axis) {
+		opts.tickCount = n
+	}
+}
+
+// MinorTicks adds unlabeled minor ticks between the axis's ticks, with
+// gridlines in the theme's MinorGridlineColor. On log scales, minor ticks
+// are at each multiple of a power of the base. Axes with ticks placed by
+// Ticks or Categories have no minor ticks.
+func MinorTicks() AxisOption {
+	return func(opts *axis) {
+		opts.minor = true
+	}
+}
+
+// RotateTickLabels rotates the axis's tick labels counterclockwise by
+// degrees, so that long labels on crowded axes, like those of categories,
+// don't overlap. Rotated labels end at their ticks. Tick labels aren't
+// rotated in polar coordinates or in text renderings.
+func RotateTickLabels(degrees float64) AxisOption {
+	return func(opts *axis) {
+		opts.labelAngle = degrees
+	}
+}
+
+// TitleScale scales the size of the axis's title by f. It has no effect on
+// text renderings.
+func TitleScale(f float64) AxisOption {
+	return func(opts *axis) {
+		opts.titleScale = f
+	}
+}
+
+// TickLabelScale scales the size of the axis's tick labels by f. It has no
+// effect on text renderings.
+func TickLabelScale(f float64) AxisOption {
+	return func(opts *axis) {
+		opts.labelScale = f
+	}
+}
+
+// Format sets the function used to label the axis's ticks, in place of
+// the default formatting for the kind of values along the axis.
+func Format(f Formatter) AxisOption {
+	return func(opts *axis) {
+		opts.format = f
+	}
+}
+
+// Categories labels the positions 0, 1, 2, and so on along the axis with
+// names, and sets the limits of the axis to fit them. Use it with the
+// positions returned by Categorize.
+func Categories(names ...string) AxisOption {
+	return func(opts *axis) {
+		opts.customTicks = make([]float64, len(names))
+		for i := range names {
+			opts.customTicks[i] = float64(i)
+		}
+		opts.format = func(x float64) string {
+			i := int(math.Round(x))
+			if i < 0 || i >= len(names) {
+				return ""
+			}
+			return names[i]
+		}
+		opts.userMin, opts.userMax = -0.5, float64(len(names))-0.5
+		opts.userLimits = true
 	}
 }
@@ -1,8 +1,15 @@
 package ggg
 
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"slices"
+)
+
 // LinePlot is a helper to create a simple line plot where the values of the series column
 // determine how to group the data.
-func LinePlot[X, Y Scalar, S comparable](d *Dataset, x Column[X], y Column[Y], series Column[S]) *Plot {
+func LinePlot[X, Y Value, S comparable](d *Dataset, x Column[X], y Column[Y], series Column[S]) *Plot {
 	return NewPlot().Layer(
 		&Layer[X, Y]{
 			Data: d,
@@ -12,3 +19,248 @@ func LinePlot[X, Y Scalar, S comparable](d *Dataset, x Column[X], y Column[Y], s
 		},
 	)
 }
+
+// ScatterPlot is a helper to create a simple scatter plot where the values of the series
+// column determine the color of the points.
+func ScatterPlot[X, Y Value, S comparable](d *Dataset, x Column[X], y Column[Y], series Column[S]) *Plot {
+	return NewPlot().Layer(
+		&Layer[X, Y]{
+			Data: d,
+			X:    x,
+			Y:    y,
+			Geom: Point(NiceColors(series), Constant(3.0)),
+		},
+	)
+}
+
+// BarPlot is a helper to create a simple bar plot with a bar for each distinct X value, as
+// tall as the mean of the Y values at it. The values of the series column determine the color
+// of the bars.
+func BarPlot[X Value, Y Scalar, S comparable](d *Dataset, x Column[X], y Column[Y], series Column[S]) *Plot {
+	return NewPlot().Layer(
+		&Layer[X, Y]{
+			Data: d,
+			X:    x,
+			Y:    y,
+			Stat: Mean[Y](),
+			Geom: Bar(NiceColors(series), Constant(0.8*minSpacing(d, x))),
+		},
+	)
+}
+
+// HistPlot is a helper to create a simple histogram of the values of x, with bins chosen by
+// Sturges' rule. The values of the series column determine how to group the data.
+func HistPlot[T Scalar, S comparable](d *Dataset, x Column[T], series Column[S]) *Plot {
+	return NewPlot().Layer(
+		&Histogram[T]{
+			Data: d,
+			X:    x,
+			Geom: Bar(NiceColors(series), Constant(0.0)),
+		},
+	)
+}
+
+// BoxPlot is a helper to create a simple box plot with a box summarizing the Y values of each
+// distinct value of the group column, positioned with Categorize. The positions are added to a
+// copy of d, leaving d unchanged.
+func BoxPlot[G comparable, Y Scalar](d *Dataset, group Column[G], y Column[Y]) *Plot {
+	d = d.clone()
+	pos, names := Categorize(d, group)
+	return NewPlot().Layer(
+		&Layer[int, Y]{
+			Data: d,
+			X:    pos,
+			Y:    y,
+			Stat: Quantiles[Y](),
+			Geom: Box(PaletteColor(0), Constant(0.6)),
+		},
+	).Presentation(XAxis(group.Name(), Categories(names...)))
+}
+
+// MeanPlot is a helper to create a line plot of the mean of the Y values at each X value,
+// surrounded by a band showing its 95% confidence interval. The values of the series column
+// determine how to group the data.
+func MeanPlot[X Value, Y Scalar, S comparable](d *Dataset, x Column[X], y Column[Y], series Column[S]) *Plot {
+	colors := NiceColors(series)
+	return NewPlot().Layer(
+		&Layer[X, Y]{
+			Data: d,
+			X:    x,
+			Y:    y,
+			Stat: ConfidenceNormal[Y](0.95),
+			Geom: Ribbon(colors),
+		},
+	).Layer(
+		&Layer[X, Y]{
+			Data: d,
+			X:    x,
+			Y:    y,
+			Stat: Mean[Y](),
+			Geom: Line(colors, Constant(2.0)),
+		},
+	)
+}
+
+// PiePlot is a helper to create a pie chart, with a slice for each row of the data sized by its
+// value. The values of the label column determine the color of the slices. Where each slice
+// starts and ends is computed into a separate dataset, leaving d unchanged. If the values don't
+// sum to a positive total, the chart has no slices.
+func PiePlot[L comparable, V Scalar](d *Dataset, label Column[L], value Column[V]) *Plot {
+	p := NewPlot().Presentation(
+		CoordPolar(),
+		XAxis("", Format(FormatPercent(0))),
+		YAxis("", Limits(-0.5, 0.5), NoTicks()),
+	)
+	var total float64
+	for v := range value.All(d) {
+		total += toFloat(v)
+	}
+	if !(total > 0) {
+		return p
+	}
+
+	// The columns are recreated for the new dataset, since each caches where
+	// it was last found.
+	pie := Empty()
+	pieLabel, pieValue := NewColumn[L](label.Name()), NewColumn[V](value.Name())
+	start := NewColumn[float64](value.Name() + " start")
+	end := NewColumn[float64](value.Name() + " end")
+	ring := NewColumn[float64](value.Name() + " ring")
+	for _, c := range []AnyColumn{pieLabel, pieValue, start, end, ring} {
+		pie.AddColumn(c)
+	}
+	pie.Grow(d.Rows())
+	var sum float64
+	for row := range d.Rows() {
+		v := value.Get(d, row)
+		pieLabel.Set(pie, row, label.Get(d, row))
+		pieValue.Set(pie, row, v)
+		start.Set(pie, row, sum/total)
+		sum += toFloat(v)
+		end.Set(pie, row, sum/total)
+	}
+	// Every slice is in one ring, which fills the circle.
+	return p.Layer(
+		&Layer[float64, float64]{
+			Data: pie,
+			X:    start,
+			Y:    ring,
+			Geom: Span(end, NiceColors(pieLabel), Constant(1.0)),
+		},
+	)
+}
+
+// minSpacing returns the smallest distance between distinct values of x, or 1 if there are
+// fewer than 2 of them.
+func minSpacing[X Value](d *Dataset, x Column[X]) float64 {
+	var xs []float64
+	for v := range x.All(d) {
+		xs = append(xs, toFloat(v))
+	}
+	slices.Sort(xs)
+	spacing := math.Inf(1)
+	for i := 1; i < len(xs); i++ {
+		if gap := xs[i] - xs[i-1]; gap > 0 {
+			spacing = min(spacing, gap)
+		}
+	}
+	if math.IsInf(spacing, 1) {
+		return 1
+	}
+	return spacing
+}
+
+// Gantt is a helper to create a timeline, with a bar spanning from start to end for each row
+// of the data. Each distinct value of the lane column gets its own row of the timeline, and
+// the values of the state column determine the color of the bars. The lanes' positions are
+// added to a copy of d, leaving d unchanged.
+func Gantt[X Value, L, S comparable](d *Dataset, start, end Column[X], lane Column[L], state Column[S]) *Plot {
+	d = d.clone()
+	pos, names := Categorize(d, lane)
+	return NewPlot().Layer(
+		&Layer[X, int]{
+			Data: d,
+			X:    start,
+			Y:    pos,
+			Geom: Span(end, NiceColors(state), Constant(0.8)),
+		},
+	).Presentation(YAxis(lane.Name(), Categories(names...)))
+}
+
+// Pairs is a helper to create a scatter-plot matrix, giving an overview of the pairwise
+// relationships between several columns. The cell at row i and column j of the matrix plots
+// cols[i] against cols[j], and the cells on the diagonal show the distribution of each column.
+func Pairs[T Scalar](d *Dataset, cols []Column[T], opts ...PairsOption) *Figure {
+	var po pairsOpts
+	for _, opt := range opts {
+		opt(&po)
+	}
+	color := po.color
+	var figOpts []FigureOption
+	if color.scale == nil {
+		color = PaletteColor(0)
+	} else {
+		figOpts = append(figOpts, ShareLegend())
+	}
+	f := NewFigure(figOpts...)
+	for i, y := range cols {
+		for j, x := range cols {
+			p := NewPlot()
+			switch {
+			case i == j && po.density:
+				p.Layer(&Density[T, T]{Data: d, Values: x, Geom: Line(color, Constant(2.0))})
+			case i == j:
+				p.Layer(&Histogram[T]{Data: d, X: x, Geom: Bar(color, Constant(0.0))})
+			case i < j && po.correlation:
+				p.Layer(Label(0, 0, fmt.Sprintf("r = %.2f", Correlation(d, x, y)))).
+					Presentation(XAxis("", Limits(-1, 1), NoTicks()), YAxis("", Limits(-1, 1), NoTicks()))
+			default:
+				p.Layer(&Layer[T, T]{Data: d, X: x, Y: y, Geom: Point(color, Constant(2.0))})
+			}
+			// Only title the axes along the edges of the matrix.
+			var xTitle, yTitle string
+			if i == len(cols)-1 {
+				xTitle = x.Name()
+			}
+			if j == 0 {
+				yTitle = y.Name()
+			}
+			p.opts.x.title, p.opts.y.title = xTitle, yTitle
+			f.Place(i, j, p)
+		}
+	}
+	return f
+}
+
+// PairsOption customizes a scatter-plot matrix created by Pairs.
+type PairsOption func(*pairsOpts)
+
+type pairsOpts struct {
+	color       Mapping[color.Color]
+	density     bool
+	correlation bool
+}
+
+// PairsColor sets the color of the points and distributions of a scatter-plot matrix, for
+// example to tell groups of rows apart. Groups share a legend below the matrix.
+func PairsColor(color Mapping[color.Color]) PairsOption {
+	return func(opts *pairsOpts) {
+		opts.color = color
+	}
+}
+
+// PairsDensity shows the distribution of each column with a density estimate, rather than a
+// histogram.
+func PairsDensity() PairsOption {
+	return func(opts *pairsOpts) {
+		opts.density = true
+	}
+}
+
+// PairsCorrelation replaces the scatter plots above the diagonal with the correlation
+// coefficient of each pair of columns.
+func PairsCorrelation() PairsOption {
+	return func(opts *pairsOpts) {
+		opts.correlation = true
+	}
+}
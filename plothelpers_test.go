@@ -0,0 +1,302 @@
+package ggg
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestPiePlot(t *testing.T) {
+	type test struct {
+		name       string
+		values     []float64
+		start, end []float64
+	}
+	for _, ts := range []test{
+		{
+			name:   "Empty",
+			values: nil,
+		},
+		{
+			name:   "Slices",
+			values: []float64{1, 2, 1},
+			start:  []float64{0, 0.25, 0.75},
+			end:    []float64{0.25, 0.75, 1},
+		},
+		{
+			name:   "Zero",
+			values: []float64{0, 0},
+		},
+		{
+			name:   "Negative",
+			values: []float64{1, -2},
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			d := Empty()
+			label, value := NewColumn[string]("label"), NewColumn[float64]("value")
+			d.AddColumn(label)
+			d.AddColumn(value)
+			for row := range d.Grow(len(ts.values)) {
+				label.Set(d, row, string(rune('a'+row)))
+				value.Set(d, row, ts.values[row])
+			}
+			p := PiePlot(d, label, value)
+			if d.Columns() != 2 {
+				t.Errorf("dataset has %d columns after PiePlot, want 2", d.Columns())
+			}
+			if ts.start == nil {
+				if len(p.layers) != 0 {
+					t.Errorf("got %d layers, want none", len(p.layers))
+				}
+				return
+			}
+			l := p.layers[0].AnyLayer.(*Layer[float64, float64])
+			got := slices.Collect(l.X.All(l.Data))
+			if !slices.Equal(got, ts.start) {
+				t.Errorf("slices start at %v, want %v", got, ts.start)
+			}
+			end := NewColumn[float64]("value end")
+			if got := slices.Collect(end.All(l.Data)); !slices.Equal(got, ts.end) {
+				t.Errorf("slices end at %v, want %v", got, ts.end)
+			}
+			if got := slices.Collect(NewColumn[string]("label").All(l.Data)); !slices.Equal(got, []string{"a", "b", "c"}) {
+				t.Errorf("slices are labeled %v, want [a b c]", got)
+			}
+		})
+	}
+}
+
+func TestBoxPlot(t *testing.T) {
+	d := Empty()
+	group, value := NewColumn[string]("group"), NewColumn[float64]("value")
+	d.AddColumn(group)
+	d.AddColumn(value)
+	for row := range d.Grow(5) {
+		group.Set(d, row, []string{"b", "a", "b", "c", "a"}[row])
+		value.Set(d, row, float64(row))
+	}
+	p := BoxPlot(d, group, value)
+	if d.Columns() != 2 {
+		t.Errorf("dataset has %d columns after BoxPlot, want 2", d.Columns())
+	}
+	l := p.layers[0].AnyLayer.(*Layer[int, float64])
+	if got, want := slices.Collect(l.X.All(l.Data)), []int{0, 1, 0, 2, 1}; !slices.Equal(got, want) {
+		t.Errorf("boxes are at %v, want %v", got, want)
+	}
+	// The plot's dataset still has d's columns, for filters and tooltips.
+	if got := slices.Collect(group.All(l.Data)); !slices.Equal(got, slices.Collect(group.All(d))) {
+		t.Errorf("plot has groups %v, want those of d", got)
+	}
+	if got, want := p.opts.x.tickLabel(2), "c"; got != want {
+		t.Errorf("got label %q at 2, want %q", got, want)
+	}
+}
+
+func TestGantt(t *testing.T) {
+	d := Empty()
+	start, end := NewColumn[float64]("start"), NewColumn[float64]("end")
+	lane, state := NewColumn[string]("lane"), NewColumn[string]("state")
+	for _, c := range []AnyColumn{start, end, lane, state} {
+		d.AddColumn(c)
+	}
+	for row := range d.Grow(4) {
+		start.Set(d, row, float64(row))
+		end.Set(d, row, float64(row)+1.5)
+		lane.Set(d, row, []string{"g1", "g2", "g1", "g3"}[row])
+		state.Set(d, row, []string{"run", "wait"}[row%2])
+	}
+	p := Gantt(d, start, end, lane, state)
+	if d.Columns() != 4 {
+		t.Errorf("dataset has %d columns after Gantt, want 4", d.Columns())
+	}
+	l := p.layers[0].AnyLayer.(*Layer[float64, int])
+	if got, want := slices.Collect(l.Y.All(l.Data)), []int{0, 1, 0, 2}; !slices.Equal(got, want) {
+		t.Errorf("bars are in lanes %v, want %v", got, want)
+	}
+	q := p.prepared()
+	q.computeRanges()
+	if x := q.opts.x; x.min != 0 || x.max != 4.5 {
+		t.Errorf("got X range [%v, %v], want [0, 4.5]", x.min, x.max)
+	}
+	if y := q.opts.y; y.min != -0.5 || y.max != 2.5 {
+		t.Errorf("got Y range [%v, %v], want [-0.5, 2.5]", y.min, y.max)
+	}
+	if got := len(p.legendEntries(testTheme(t))); got != 2 {
+		t.Errorf("got %d legend entries, want one for each state", got)
+	}
+}
+
+// seriesData returns a dataset of x, y and series columns, with a row for
+// each of xs and ys and series alternating between "a" and "b".
+func seriesData(xs, ys []float64) (*Dataset, Column[float64], Column[float64], Column[string]) {
+	d := Empty()
+	x, y, s := NewColumn[float64]("x"), NewColumn[float64]("y"), NewColumn[string]("s")
+	d.AddColumn(x)
+	d.AddColumn(y)
+	d.AddColumn(s)
+	for row := range d.Grow(len(xs)) {
+		x.Set(d, row, xs[row])
+		y.Set(d, row, ys[row])
+		s.Set(d, row, []string{"a", "b"}[row%2])
+	}
+	return d, x, y, s
+}
+
+func TestBarPlot(t *testing.T) {
+	d, x, y, s := seriesData([]float64{0, 0, 2, 2, 4, 4}, []float64{1, 3, 2, 4, 5, 7})
+	p := BarPlot(d, x, y, s)
+	l := p.layers[0].AnyLayer.(*Layer[float64, float64])
+	if got := l.Stat.Apply(slices.Values([]float64{1, 3})); !slices.Equal(got, []float64{2}) {
+		t.Errorf("bars are %v tall for values 1 and 3, want their mean", got)
+	}
+	// Bars fill 80% of the space between X values, and start at zero.
+	if got := l.Geom.maxSize(d); got != 1.6 {
+		t.Errorf("got bars %v wide, want 1.6", got)
+	}
+	q := p.prepared()
+	q.computeRanges()
+	if x := q.opts.x; x.min != -0.8 || x.max != 4.8 {
+		t.Errorf("got X range [%v, %v], want [-0.8, 4.8]", x.min, x.max)
+	}
+	if y := q.opts.y; y.min != 0 || y.max != 7 {
+		t.Errorf("got Y range [%v, %v], want [0, 7]", y.min, y.max)
+	}
+}
+
+func TestHistPlot(t *testing.T) {
+	xs := make([]float64, 16)
+	for i := range xs {
+		xs[i] = float64(i)
+	}
+	d, x, _, s := seriesData(xs, xs)
+	h := HistPlot(d, x, s).layers[0].AnyLayer.(*Histogram[float64])
+	edges, ss := h.bins()
+	// Sturges' rule gives log₂ 16 + 1 bins.
+	if len(edges) != 6 || edges[0] != 0 || edges[5] != 15 {
+		t.Errorf("got bin edges %v, want 5 bins from 0 to 15", edges)
+	}
+	if len(ss) != 2 {
+		t.Errorf("got %d series, want one for each value of s", len(ss))
+	}
+}
+
+func TestMeanPlot(t *testing.T) {
+	d, x, y, s := seriesData([]float64{0, 0, 0, 0, 1, 1, 1, 1}, []float64{1, 2, 3, 4, 2, 4, 6, 8})
+	p := MeanPlot(d, x, y, s)
+	if len(p.layers) != 2 {
+		t.Fatalf("got %d layers, want a band and a line", len(p.layers))
+	}
+	band := p.layers[0].AnyLayer.(*Layer[float64, float64])
+	line := p.layers[1].AnyLayer.(*Layer[float64, float64])
+	if band.Geom.kind != kindRibbon || band.Stat.Dimensions() != 2 {
+		t.Errorf("band has geom %v and %d-dimensional statistic, want a ribbon and an interval", band.Geom.kind, band.Stat.Dimensions())
+	}
+	if line.Geom.kind != kindLine || line.Stat.Dimensions() != 1 {
+		t.Errorf("line has geom %v and %d-dimensional statistic, want a line and a mean", line.Geom.kind, line.Stat.Dimensions())
+	}
+	if err := p.draw(newRasterCanvas(300, 200), testTheme(t), 300, 200); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPairs(t *testing.T) {
+	d := Empty()
+	a, b, c := NewColumn[float64]("a"), NewColumn[float64]("b"), NewColumn[float64]("c")
+	group := NewColumn[string]("group")
+	for _, col := range []AnyColumn{a, b, c, group} {
+		d.AddColumn(col)
+	}
+	for row := range d.Grow(8) {
+		v := float64(row)
+		a.Set(d, row, v)
+		b.Set(d, row, 2*v+1)
+		c.Set(d, row, -v*v)
+		group.Set(d, row, []string{"x", "y"}[row%2])
+	}
+	cols := []Column[float64]{a, b, c}
+
+	type test struct {
+		name string
+		opts []PairsOption
+
+		// diagonal and upper describe the layer of each cell on and above
+		// the diagonal.
+		diagonal, upper string
+		shareLegend     bool
+	}
+	for _, ts := range []test{
+		{
+			name:     "Default",
+			diagonal: "*ggg.Histogram[float64]",
+			upper:    "*ggg.Layer[float64,float64]",
+		},
+		{
+			name:     "Density",
+			opts:     []PairsOption{PairsDensity()},
+			diagonal: "*ggg.Density[float64,float64]",
+			upper:    "*ggg.Layer[float64,float64]",
+		},
+		{
+			name:     "Correlation",
+			opts:     []PairsOption{PairsCorrelation()},
+			diagonal: "*ggg.Histogram[float64]",
+			upper:    "*ggg.annotation",
+		},
+		{
+			name:        "Color",
+			opts:        []PairsOption{PairsColor(NiceColors(group))},
+			diagonal:    "*ggg.Histogram[float64]",
+			upper:       "*ggg.Layer[float64,float64]",
+			shareLegend: true,
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			f := Pairs(d, cols, ts.opts...)
+			if len(f.cells) != 9 {
+				t.Fatalf("got %d cells, want 9", len(f.cells))
+			}
+			if f.shareLegend != ts.shareLegend {
+				t.Errorf("got shared legend %t, want %t", f.shareLegend, ts.shareLegend)
+			}
+			for _, cell := range f.cells {
+				p := cell.plot
+				want := "*ggg.Layer[float64,float64]"
+				switch {
+				case cell.row == cell.col:
+					want = ts.diagonal
+				case cell.row < cell.col:
+					want = ts.upper
+				}
+				if got := fmt.Sprintf("%T", p.layers[0].AnyLayer); got != want {
+					t.Errorf("cell (%d, %d) has a %s, want a %s", cell.row, cell.col, got, want)
+				}
+				// Only the axes along the left and bottom edges are titled.
+				var xTitle, yTitle string
+				if cell.row == 2 {
+					xTitle = cols[cell.col].Name()
+				}
+				if cell.col == 0 {
+					yTitle = cols[cell.row].Name()
+				}
+				if p.opts.x.title != xTitle || p.opts.y.title != yTitle {
+					t.Errorf("cell (%d, %d) has axis titles %q and %q, want %q and %q",
+						cell.row, cell.col, p.opts.x.title, p.opts.y.title, xTitle, yTitle)
+				}
+			}
+			if err := f.draw(newRasterCanvas(600, 600), testTheme(t), 600, 600); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	// The correlation above the diagonal is that of the cell's columns.
+	f := Pairs(d, cols, PairsCorrelation())
+	for _, cell := range f.cells {
+		if cell.row == 0 && cell.col == 1 {
+			if got := cell.plot.layers[0].AnyLayer.(*annotation).text; got != "r = 1.00" {
+				t.Errorf("got label %q for a and b, want \"r = 1.00\"", got)
+			}
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package ggg
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/fogleman/gg"
+	"github.com/golang/freetype/truetype"
+)
+
+// rasterCanvas is a canvas that draws into an image.
+type rasterCanvas struct {
+	c *gg.Context
+	faceCache
+}
+
+func newRasterCanvas(width, height int) *rasterCanvas {
+	return &rasterCanvas{c: gg.NewContext(width, height), faceCache: make(faceCache)}
+}
+
+func (rc *rasterCanvas) image() image.Image {
+	return rc.c.Image()
+}
+
+func (rc *rasterCanvas) setColor(c color.Color) {
+	rc.c.SetColor(c)
+}
+
+func (rc *rasterCanvas) setLineWidth(w float64) {
+	rc.c.SetLineWidth(w)
+}
+
+func (rc *rasterCanvas) setLineCap(lc lineCap) {
+	switch lc {
+	case lineCapRound:
+		rc.c.SetLineCap(gg.LineCapRound)
+	case lineCapButt:
+		rc.c.SetLineCap(gg.LineCapButt)
+	case lineCapSquare:
+		rc.c.SetLineCap(gg.LineCapSquare)
+	}
+}
+
+func (rc *rasterCanvas) setLineJoin(lj lineJoin) {
+	switch lj {
+	case lineJoinRound:
+		rc.c.SetLineJoin(gg.LineJoinRound)
+	case lineJoinBevel:
+		rc.c.SetLineJoin(gg.LineJoinBevel)
+	}
+}
+
+func (rc *rasterCanvas) setFont(f *truetype.Font, size float64) {
+	rc.c.SetFontFace(rc.face(f, size))
+}
+
+func (rc *rasterCanvas) moveTo(x, y float64) {
+	rc.c.MoveTo(x, y)
+}
+
+func (rc *rasterCanvas) lineTo(x, y float64) {
+	rc.c.LineTo(x, y)
+}
+
+func (rc *rasterCanvas) closePath() {
+	rc.c.ClosePath()
+}
+
+func (rc *rasterCanvas) circle(x, y, r float64) {
+	rc.c.DrawCircle(x, y, r)
+}
+
+func (rc *rasterCanvas) rect(x, y, w, h float64) {
+	rc.c.DrawRectangle(x, y, w, h)
+}
+
+func (rc *rasterCanvas) stroke() {
+	rc.c.Stroke()
+}
+
+func (rc *rasterCanvas) fill() {
+	rc.c.Fill()
+}
+
+func (rc *rasterCanvas) drawString(s string, x, y float64) {
+	rc.c.DrawString(s, x, y)
+}
+
+func (rc *rasterCanvas) push() {
+	rc.c.Push()
+}
+
+func (rc *rasterCanvas) pop() {
+	rc.c.Pop()
+}
+
+func (rc *rasterCanvas) translate(x, y float64) {
+	rc.c.Translate(x, y)
+}
+
+func (rc *rasterCanvas) rotate(angle float64) {
+	rc.c.Rotate(angle)
+}
@@ -1,186 +1,717 @@
 package ggg
 
 import (
+	"cmp"
 	"fmt"
 	"image"
+	"image/color"
 	"math"
 	"slices"
 	"strconv"
-
-	"github.com/fogleman/gg"
-	"github.com/golang/freetype/truetype"
 )
 
+// defaultWidth and defaultHeight are the size of plots rendered by methods
+// that don't take a size.
+const defaultWidth, defaultHeight = 1080, 720
+
 func (p *Plot) Render(theme string, width, height int) (image.Image, error) {
+	th, err := lookupTheme(theme)
+	if err != nil {
+		return nil, err
+	}
+	rc := newRasterCanvas(width, height)
+	if err := p.draw(rc, th, float64(width), float64(height)); err != nil {
+		return nil, err
+	}
+	return rc.image(), nil
+}
+
+func lookupTheme(theme string) (*Theme, error) {
 	th, ok := themes[theme]
 	if !ok {
 		return nil, fmt.Errorf("unknown theme %s", theme)
 	}
-	c := gg.NewContext(width, height)
+	return th, nil
+}
+
+// draw draws the plot onto c, filling a w by h area at the origin.
+func (p *Plot) draw(c canvas, th *Theme, w, h float64) error {
+	y2 := p.usesY2()
+	if y2 && p.opts.coord != coordCartesian {
+		return fmt.Errorf("secondary Y axis can only be used with Cartesian coordinates")
+	}
+	// Keep lines and points visible in small plots, like those in a figure.
+	thickness := max(1, math.Round(math.Sqrt(w*h/(1080*720))))
 
-	// Basic dimensional definitions.
-	w, h := float64(width), float64(height)
-	var (
-		padTop   = w / 10.0
-		padLeft  = h / 8.0
-		padRight = w / 20.0
-		padBot   = h / 8.0
-	)
-	thickness := math.Round(math.Sqrt(w * h / (1080 * 720)))
+	p = p.prepared()
+	if len(p.layers) != 0 {
+		p.computeRanges()
+		if err := p.checkAxes(); err != nil {
+			return err
+		}
+	}
+	l := p.layout(c, th, w, h, y2)
+	area := l.area
 
-	titleFont := truetype.NewFace(th.TitleFont, &truetype.Options{Size: math.Round(h / 15), SubPixelsX: 32, SubPixelsY: 8})
-	axisFont := truetype.NewFace(th.AxisFont, &truetype.Options{Size: math.Round(h / 30), SubPixelsX: 32, SubPixelsY: 8})
-	annotationFont := truetype.NewFace(th.AnnotationFont, &truetype.Options{Size: math.Round(h / 50), SubPixelsX: 32, SubPixelsY: 8})
+	// Polar plots are drawn in a circle in the middle of the chart area.
+	cx, cy := (area.x0+area.x1)/2, (area.y0+area.y1)/2
+	radius := l.radius
 
 	// Background color.
-	c.DrawRectangle(0, 0, w, h)
-	c.SetColor(th.BorderBackgroundColor)
-	c.Fill()
-	c.DrawRectangle(padLeft, padTop, w-padLeft-padRight, h-padTop-padBot)
-	c.SetColor(th.ChartBackgroundColor)
-	c.Fill()
+	c.rect(0, 0, w, h)
+	c.setColor(th.BorderBackgroundColor)
+	c.fill()
+	beginGroup(c, "chart", "")
+	if p.opts.coord == coordPolar {
+		c.circle(cx, cy, radius)
+	} else {
+		c.rect(area.x0, area.y0, area.x1-area.x0, area.y1-area.y0)
+	}
+	c.setColor(th.ChartBackgroundColor)
+	c.fill()
+	endGroup(c)
 
 	// Axis titles.
-	c.SetFontFace(axisFont)
-	c.SetColor(th.ForegroundColor)
-	c.DrawStringWrapped(p.opts.x.title, (w-padLeft-padRight)/2+padLeft, h-padBot/2, 0.5, 0.5, w-padLeft-padRight, 8, gg.AlignCenter)
-	c.Push()
-	c.Translate(padLeft/4, (h-padTop-padBot)/2+padTop)
-	c.Rotate(-math.Pi / 2)
-	c.DrawStringWrapped(p.opts.y.title, 0, 0, 0.5, 0.5, w-padLeft-padRight, 8, gg.AlignCenter)
-	c.Pop()
-
-	// Plot title.
-	c.SetFontFace(titleFont)
-	c.SetColor(th.ForegroundColor)
-	c.DrawStringWrapped(p.opts.title, padLeft/2, padTop/4, 0, 0, w-padRight, 8, gg.AlignLeft)
+	bottom, left := p.bottomLeft()
+	c.setColor(th.ForegroundColor)
+	drawTextWrapped(c, l.titleFontOf(bottom), bottom.title, (area.x0+area.x1)/2, l.bottomTitleY, 0.5, 0, l.bottomTitleWidth, lineSpacing, alignCenter)
+	c.push()
+	c.translate(l.leftTitleX, (area.y0+area.y1)/2)
+	c.rotate(-math.Pi / 2)
+	drawTextWrapped(c, l.titleFontOf(left), left.title, 0, 0, 0.5, 0.5, l.leftTitleWidth, lineSpacing, alignCenter)
+	c.pop()
+	if y2 {
+		c.push()
+		c.translate(l.rightTitleX, (area.y0+area.y1)/2)
+		c.rotate(-math.Pi / 2)
+		drawTextWrapped(c, l.titleFontOf(&p.opts.y2), p.opts.y2.title, 0, 0, 0.5, 0.5, l.leftTitleWidth, lineSpacing, alignCenter)
+		c.pop()
+	}
+
+	// Plot title, subtitle and caption.
+	drawTextWrapped(c, l.titleFont, p.opts.title, l.margin, l.titleY, 0, 0, l.textWidth-l.frameWidth-l.margin, lineSpacing, alignLeft)
+	if p.opts.frame != "" {
+		drawText(c, l.axisFont, p.opts.frame, w-l.margin, l.titleY, 1, 1)
+	}
+	drawTextWrapped(c, l.subtitleFont, p.opts.subtitle, l.margin, l.subtitleY, 0, 0, l.textWidth, lineSpacing, alignLeft)
+	drawTextWrapped(c, l.annotationFont, p.opts.caption, w-l.margin, l.captionY, 1, 0, l.textWidth, lineSpacing, alignRight)
 
 	// If there are no layers, there's nothing else to draw.
 	if len(p.layers) == 0 {
-		return c.Image(), nil
+		return nil
 	}
 
-	// Determine x/y ranges.
-	if p.opts.x.userLimits {
-		p.opts.x.min, p.opts.x.max = p.opts.x.userMin, p.opts.x.userMax
-	} else {
-		p.opts.x.min = math.Inf(1)
-		p.opts.x.max = math.Inf(-1)
-		for _, l := range p.layers {
-			lo, hi := l.xRange()
-			p.opts.x.min = min(p.opts.x.min, lo)
-			p.opts.x.max = max(p.opts.x.max, hi)
+	// Set up the scales and the canvas layers are drawn onto, then draw
+	// the axes to match. Layers are drawn as if the plot were Cartesian,
+	// onto a canvas that transforms their coordinates.
+	var xScale, yScale, y2Scale scaleFunc
+	var err error
+	data, dataArea := c, area
+	switch p.opts.coord {
+	case coordCartesian:
+		xScale, yScale, y2Scale, err = p.scales(area.x0, area.x1, area.y0, area.y1)
+		if err != nil {
+			return err
+		}
+		p.drawAxes(c, th, l, thickness, xScale, yScale, y2Scale)
+	case coordFlip:
+		// X runs up the plot, and Y from left to right.
+		xScale, yScale, _, err = p.scales(area.y1, area.y0, area.x1, area.x0)
+		if err != nil {
+			return err
+		}
+		p.drawAxes(c, th, l, thickness, yScale, xScale, nil)
+		data = newCoordCanvas(c, func(x, y float64) (float64, float64) {
+			return y, x
+		}, false)
+		dataArea = chartArea{area.y0, area.x0, area.y1, area.x1}
+	case coordPolar:
+		// X is the distance around the edge of the circle, and Y the
+		// distance from its center.
+		if xScale, err = p.opts.x.scale("X", 0, 2*math.Pi*radius); err != nil {
+			return err
+		}
+		if yScale, err = p.opts.y.scale("Y", 0, radius); err != nil {
+			return err
 		}
+		p.drawPolarAxes(c, th, cx, cy, radius, l, thickness, xScale, yScale)
+		data = newCoordCanvas(c, func(x, y float64) (float64, float64) {
+			sin, cos := math.Sincos(x / radius)
+			return cx + y*sin, cy - y*cos
+		}, true)
+		dataArea = chartArea{0, 0, 2 * math.Pi * radius, radius}
 	}
-	if p.opts.y.userLimits {
-		p.opts.y.min, p.opts.y.max = p.opts.y.userMin, p.opts.y.userMax
-	} else {
-		p.opts.y.min = math.Inf(1)
-		p.opts.y.max = math.Inf(-1)
-		for _, l := range p.layers {
-			lo, hi := l.yRange()
-			p.opts.y.min = min(p.opts.y.min, lo)
-			p.opts.y.max = max(p.opts.y.max, hi)
+
+	// Draw layers.
+	legend := p.legendEntries(th)
+	beginGroup(c, "data", "")
+	for _, l := range p.layers {
+		c.push()
+		c.setLineCap(lineCapRound)
+		c.setLineJoin(lineJoinRound)
+		ys := yScale
+		if l.axis == Y2 {
+			ys = y2Scale
+		}
+		err := renderLayer(data, th, l.AnyLayer, xScale, ys, thickness, dataArea)
+		c.pop()
+		if err != nil {
+			return err
 		}
 	}
+	endGroup(c)
 
-	// Set the scaling functions for x/y.
-	var xScale, yScale scaleFunc
-	if p.opts.x.logBase != 0 {
-		if p.opts.x.min <= 0 || p.opts.x.max <= 0 {
-			return nil, fmt.Errorf("specified log scale, but domain of X values is zero or negative: [%f, %f]", p.opts.x.min, p.opts.x.max)
+	// Draw the legend over the data.
+	p.drawLegend(c, th, legend, l.annotationFont, thickness, area.x0, area.y0, area.x1-area.x0, area.y1-area.y0)
+	return nil
+}
+
+// drawAxes draws the gridlines, ticks and lines of the axes along the
+// bottom, left and, if rightScale isn't nil, right of the chart area laid
+// out by l. The scales place ticks along the axes, and are those of the X,
+// Y and secondary Y axes, unless the plot is flipped, in which case the
+// bottom axis is the Y axis.
+func (p *Plot) drawAxes(c canvas, th *Theme, l *plotLayout, thickness float64, bottomScale, leftScale, rightScale scaleFunc) {
+	area, tick := l.area, l.tick
+	bottom, left := p.bottomLeft()
+	bottomTicks := bottom.ticks()
+	leftTicks := left.ticks()
+	bottomMinor := bottom.minorTicks(bottomTicks)
+	leftMinor := left.minorTicks(leftTicks)
+
+	// Draw gridlines. The bottom axis's gridlines and ticks are grouped
+	// separately, so that interactive canvases may zoom along it.
+	c.setLineCap(lineCapSquare)
+	c.setLineJoin(lineJoinBevel)
+	c.setLineWidth(1)
+	beginGroup(c, "x-axis", "")
+	if bottomMinor != nil {
+		c.setColor(minorGridlineColor(th))
+		for _, x := range bottomMinor {
+			dx := bottomScale(x)
+			c.moveTo(dx, area.y1)
+			c.lineTo(dx, area.y0)
 		}
-		xScale = scaleLog(p.opts.x.logBase, p.opts.x.min, p.opts.x.max, padLeft, w-padRight)
-	} else {
-		xScale = scaleLinear(p.opts.x.min, p.opts.x.max, padLeft, w-padRight)
+		c.stroke()
+	}
+	c.setColor(th.GridlineColor)
+	for _, x := range bottomTicks {
+		dx := bottomScale(x)
+		c.moveTo(dx, area.y1)
+		c.lineTo(dx, area.y0)
 	}
-	if p.opts.y.logBase != 0 {
-		if p.opts.y.min <= 0 || p.opts.y.max <= 0 {
-			return nil, fmt.Errorf("specified log scale, but domain of Y values is zero or negative: [%f, %f]", p.opts.y.min, p.opts.y.max)
+	c.stroke()
+	endGroup(c)
+	if leftMinor != nil {
+		c.setColor(minorGridlineColor(th))
+		for _, y := range leftMinor {
+			dy := leftScale(y)
+			c.moveTo(area.x0, dy)
+			c.lineTo(area.x1, dy)
 		}
-		yScale = scaleLog(p.opts.y.logBase, p.opts.y.max, p.opts.y.min, padTop, h-padBot)
-	} else {
-		yScale = scaleLinear(p.opts.y.max, p.opts.y.min, padTop, h-padBot)
+		c.stroke()
+	}
+	c.setColor(th.GridlineColor)
+	for _, y := range leftTicks {
+		dy := leftScale(y)
+		c.moveTo(area.x0, dy)
+		c.lineTo(area.x1, dy)
 	}
+	c.stroke()
 
-	var xTicks, yTicks []float64
-	if len(p.opts.x.customTicks) != 0 {
-		xTicks = p.opts.x.customTicks
-	} else if p.opts.x.logBase != 0 {
-		xTicks = logTicks(p.opts.x.logBase, p.opts.x.min, p.opts.x.max)
-	} else {
-		xTicks = linearTicks(p.opts.x.min, p.opts.x.max, 5)
+	// Basic axes.
+	c.setLineWidth(2 * thickness)
+	c.setColor(th.ForegroundColor)
+	c.moveTo(area.x0, area.y1)
+	c.lineTo(area.x1, area.y1)
+	c.moveTo(area.x0, area.y1)
+	c.lineTo(area.x0, area.y0)
+	if rightScale != nil {
+		c.moveTo(area.x1, area.y1)
+		c.lineTo(area.x1, area.y0)
 	}
-	if len(p.opts.y.customTicks) != 0 {
-		yTicks = p.opts.y.customTicks
-	} else if p.opts.y.logBase != 0 {
-		yTicks = logTicks(p.opts.y.logBase, p.opts.y.min, p.opts.y.max)
-	} else {
-		yTicks = linearTicks(p.opts.y.min, p.opts.y.max, 5)
+	c.stroke()
+
+	// Draw ticks.
+	beginGroup(c, "x-axis", "")
+	// Rotated labels end at their ticks.
+	ax, ay := 0.5, 1.0
+	switch {
+	case bottom.labelAngle > 0:
+		ax, ay = 1, 0.5
+	case bottom.labelAngle < 0:
+		ax, ay = 0, 0.5
+	}
+	for _, x := range bottomTicks {
+		dx := bottomScale(x)
+		c.moveTo(dx, area.y1)
+		c.lineTo(dx, area.y1+tick)
+		drawTextRotated(c, l.labelFontOf(bottom), bottom.tickLabel(x), dx, area.y1+2*tick, ax, ay, bottom.labelAngle)
+	}
+	for _, x := range bottomMinor {
+		dx := bottomScale(x)
+		c.moveTo(dx, area.y1)
+		c.lineTo(dx, area.y1+tick/2)
+	}
+	c.stroke()
+	endGroup(c)
+	for _, y := range leftTicks {
+		dy := leftScale(y)
+		c.moveTo(area.x0, dy)
+		c.lineTo(area.x0-tick, dy)
+		drawTextRotated(c, l.labelFontOf(left), left.tickLabel(y), area.x0-2*tick, dy, 1, 0.5, left.labelAngle)
 	}
+	for _, y := range leftMinor {
+		dy := leftScale(y)
+		c.moveTo(area.x0, dy)
+		c.lineTo(area.x0-tick/2, dy)
+	}
+	if rightScale != nil {
+		rightTicks := p.opts.y2.ticks()
+		for _, y := range rightTicks {
+			dy := rightScale(y)
+			c.moveTo(area.x1, dy)
+			c.lineTo(area.x1+tick, dy)
+			drawTextRotated(c, l.labelFontOf(&p.opts.y2), p.opts.y2.tickLabel(y), area.x1+2*tick, dy, 0, 0.5, p.opts.y2.labelAngle)
+		}
+		for _, y := range p.opts.y2.minorTicks(rightTicks) {
+			dy := rightScale(y)
+			c.moveTo(area.x1, dy)
+			c.lineTo(area.x1+tick/2, dy)
+		}
+	}
+	c.stroke()
+
+	// Break the axis lines where values are skipped.
+	size := tick
+	for _, dx := range bottom.breakPositions(bottomScale) {
+		drawBreak(c, th, dx, area.y1, size, thickness, false)
+	}
+	for _, dy := range left.breakPositions(leftScale) {
+		drawBreak(c, th, area.x0, dy, size, thickness, true)
+	}
+	if rightScale != nil {
+		for _, dy := range p.opts.y2.breakPositions(rightScale) {
+			drawBreak(c, th, area.x1, dy, size, thickness, true)
+		}
+	}
+}
 
-	// Draw gridlines.
-	c.SetColor(th.GridlineColor)
+// drawBreak marks a break in an axis line at (x, y) with a gap between two
+// slanted lines. vertical is set if the axis line is vertical.
+func drawBreak(c canvas, th *Theme, x, y, size, thickness float64, vertical bool) {
+	// Along and across the axis line.
+	ax, ay, cx, cy := 1.0, 0.0, 0.0, 1.0
+	if vertical {
+		ax, ay, cx, cy = 0, 1, 1, 0
+	}
+	gap := size / 2
+	c.setColor(th.BorderBackgroundColor)
+	c.rect(x-ax*gap-cx*thickness*2, y-ay*gap-cy*thickness*2, 2*ax*gap+4*cx*thickness, 2*ay*gap+4*cy*thickness)
+	c.fill()
+	c.setColor(th.ForegroundColor)
+	for _, d := range []float64{-gap, gap} {
+		c.moveTo(x+ax*(d-size/2)-cx*size, y+ay*(d-size/2)-cy*size)
+		c.lineTo(x+ax*(d+size/2)+cx*size, y+ay*(d+size/2)+cy*size)
+	}
+	c.stroke()
+}
+
+// minorGridlineColor returns the color of minor gridlines in th.
+func minorGridlineColor(th *Theme) color.Color {
+	if th.MinorGridlineColor != nil {
+		return th.MinorGridlineColor
+	}
+	return th.GridlineColor
+}
+
+// drawPolarAxes draws the gridlines and ticks of polar axes, in a circle
+// centered on (cx, cy). Gridlines for the X axis run out from the center,
+// and those for the Y axis go around it.
+func (p *Plot) drawPolarAxes(c canvas, th *Theme, cx, cy, radius float64, l *plotLayout, thickness float64, xScale, yScale scaleFunc) {
+	// Ticks at the end of the X axis are at the same angle as those at the
+	// start, so skip them.
+	var xTicks []float64
+	for _, x := range p.opts.x.ticks() {
+		if a := xScale(x) / radius; a >= -1e-9 && a < 2*math.Pi-1e-9 {
+			xTicks = append(xTicks, x)
+		}
+	}
+	var yTicks []float64
+	for _, y := range p.opts.y.ticks() {
+		if r := yScale(y); r >= -1e-9 && r <= radius+1e-9 {
+			yTicks = append(yTicks, y)
+		}
+	}
+
+	c.setLineCap(lineCapSquare)
+	c.setLineJoin(lineJoinBevel)
+	c.setLineWidth(1)
+	c.setColor(minorGridlineColor(th))
+	for _, x := range p.opts.x.minorTicks(p.opts.x.ticks()) {
+		sin, cos := math.Sincos(xScale(x) / radius)
+		c.moveTo(cx, cy)
+		c.lineTo(cx+radius*sin, cy-radius*cos)
+	}
+	c.stroke()
+	for _, y := range p.opts.y.minorTicks(p.opts.y.ticks()) {
+		if r := yScale(y); r > 0 && r < radius {
+			c.circle(cx, cy, r)
+			c.stroke()
+		}
+	}
+	c.setColor(th.GridlineColor)
 	for _, x := range xTicks {
-		dx := xScale(x)
-		c.DrawLine(dx, h-padBot, dx, padTop)
+		sin, cos := math.Sincos(xScale(x) / radius)
+		c.moveTo(cx, cy)
+		c.lineTo(cx+radius*sin, cy-radius*cos)
 	}
+	c.stroke()
 	for _, y := range yTicks {
-		dy := yScale(y)
-		c.DrawLine(padLeft, dy, w-padRight, dy)
+		c.circle(cx, cy, yScale(y))
+		c.stroke()
 	}
-	c.Stroke()
-
-	// Basic axes.
-	c.SetLineCap(gg.LineCapSquare)
-	c.SetLineJoin(gg.LineJoinBevel)
-	c.SetLineWidth(2 * thickness)
-	c.SetColor(th.ForegroundColor)
-	c.DrawLine(padLeft, h-padBot, w-padRight, h-padBot)
-	c.DrawLine(padLeft, h-padBot, padLeft, padTop)
-	c.Stroke()
 
-	// Draw ticks.
-	c.SetColor(th.ForegroundColor)
-	c.SetFontFace(annotationFont)
+	c.setColor(th.ForegroundColor)
+	pad := fontHeight(c, l.annotationFont) / 2
 	for _, x := range xTicks {
-		dx := xScale(x)
-		c.DrawLine(dx, h-padBot, dx, h-padBot+padBot/10)
-		c.DrawStringWrapped(strconv.FormatFloat(x, 'g', 3, 64), dx, h-padBot+padBot/5, 0.5, 0.5, (w-padLeft-padRight)/float64(len(xTicks)), 8, gg.AlignCenter)
+		sin, cos := math.Sincos(xScale(x) / radius)
+		r := radius + pad
+		drawText(c, l.labelFontOf(&p.opts.x), p.opts.x.tickLabel(x), cx+r*sin, cy-r*cos, 0.5-0.5*sin, 0.5-0.5*cos)
 	}
 	for _, y := range yTicks {
-		dy := yScale(y)
-		c.DrawLine(padLeft, dy, padLeft-padLeft/10, dy)
-		c.DrawStringWrapped(strconv.FormatFloat(y, 'g', 3, 64), padLeft-padLeft/5, dy, 1, 0.5, padLeft, 8, gg.AlignRight)
+		drawText(c, l.labelFontOf(&p.opts.y), p.opts.y.tickLabel(y), cx-pad/2, cy-yScale(y), 1, 0.5)
 	}
-	c.Stroke()
+	c.setLineWidth(2 * thickness)
+	c.circle(cx, cy, radius)
+	c.stroke()
+}
 
-	// Draw layers.
+// chartArea is the extent of the area data is drawn into.
+type chartArea struct {
+	x0, y0, x1, y1 float64
+}
+
+// areaLayer is implemented by layers that draw relative to the extent of
+// the chart area, rather than only at positions along the axes.
+type areaLayer interface {
+	renderArea(c canvas, theme *Theme, xScale, yScale scaleFunc, scaleFactor float64, area chartArea) error
+}
+
+// preparer is implemented by layers that compute statistics needed by
+// several of their methods. prepare returns a copy of the layer that
+// computes them once, to be used for a single render.
+type preparer interface {
+	prepare() AnyLayer
+}
+
+// prepared returns a copy of p with its layers prepared for rendering.
+func (p *Plot) prepared() *Plot {
+	q := *p
+	q.layers = make([]plotLayer, len(p.layers))
+	for i, l := range p.layers {
+		if pl, ok := l.AnyLayer.(preparer); ok {
+			l.AnyLayer = pl.prepare()
+		}
+		q.layers[i] = l
+	}
+	return &q
+}
+
+// renderLayer renders l onto c, in the chart area.
+func renderLayer(c canvas, theme *Theme, l AnyLayer, xScale, yScale scaleFunc, scaleFactor float64, area chartArea) error {
+	if al, ok := l.(areaLayer); ok {
+		return al.renderArea(c, theme, xScale, yScale, scaleFactor, area)
+	}
+	return l.render(c, theme, xScale, yScale, scaleFactor)
+}
+
+// computeRanges determines the ranges of the plot's axes, and how to
+// interpret their values, from its layers.
+func (p *Plot) computeRanges() {
+	var y1, y2 []plotLayer
 	for _, l := range p.layers {
-		im, err := l.render(th, width, height, xScale, yScale)
-		if err != nil {
-			return nil, err
+		if l.axis == Y2 {
+			y2 = append(y2, l)
+		} else {
+			y1 = append(y1, l)
 		}
-		c.DrawImage(im, 0, 0)
 	}
+	p.opts.x.kind = layersKind(p.layers, AnyLayer.xKind)
+	p.opts.x.fit(p.layers, AnyLayer.xRange)
+	if len(y1) != 0 {
+		p.opts.y.kind = layersKind(y1, AnyLayer.yKind)
+		p.opts.y.fit(y1, AnyLayer.yRange)
+	}
+	if len(y2) != 0 {
+		p.opts.y2.kind = layersKind(y2, AnyLayer.yKind)
+		p.opts.y2.fit(y2, AnyLayer.yRange)
+	}
+}
 
-	return c.Image(), nil
+// layersKind returns the kind of values along an axis, from the first of
+// layers that has values along it.
+func layersKind(layers []plotLayer, layerKind func(AnyLayer) valueKind) valueKind {
+	for _, l := range layers {
+		if k := layerKind(l.AnyLayer); k != valueNone {
+			return k
+		}
+	}
+	return valueNumber
 }
 
-type scaleFunc func(float64) float64
+// fit sets the range of the axis to the user's limits, if any, or else to
+// cover the ranges of layers.
+func (a *axis) fit(layers []plotLayer, layerRange func(AnyLayer) (lo, hi float64)) {
+	if a.userLimits {
+		a.min, a.max = a.userMin, a.userMax
+		return
+	}
+	a.min = math.Inf(1)
+	a.max = math.Inf(-1)
+	for _, l := range layers {
+		lo, hi := layerRange(l.AnyLayer)
+		a.min = min(a.min, lo)
+		a.max = max(a.max, hi)
+	}
+	if a.min > a.max {
+		// No layer has values along the axis.
+		a.min, a.max = 0, 1
+	}
+}
 
-func scaleLinear(x0, x1, t0, t1 float64) scaleFunc {
+// scales returns functions mapping X values onto [x0, x1] and Y values onto
+// [y1, y0], so that larger Y values are higher up. y2Scale maps values on
+// the secondary Y axis, and is nil if it's unused.
+func (p *Plot) scales(x0, x1, y0, y1 float64) (xScale, yScale, y2Scale scaleFunc, err error) {
+	if xScale, err = p.opts.x.scale("X", x0, x1); err != nil {
+		return nil, nil, nil, err
+	}
+	if yScale, err = p.opts.y.scale("Y", y1, y0); err != nil {
+		return nil, nil, nil, err
+	}
+	if p.usesY2() {
+		if y2Scale, err = p.opts.y2.scale("Y2", y1, y0); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return xScale, yScale, y2Scale, nil
+}
+
+// checkAxes returns an error if the axes' transforms can't map their
+// ranges. It must be called before ticks are placed along the axes.
+func (p *Plot) checkAxes() error {
+	if err := p.opts.x.check("X"); err != nil {
+		return err
+	}
+	if err := p.opts.y.check("Y"); err != nil {
+		return err
+	}
+	if p.usesY2() {
+		return p.opts.y2.check("Y2")
+	}
+	return nil
+}
+
+// check returns an error if the axis's transform can't map its range. name
+// identifies the axis in errors.
+func (a *axis) check(name string) error {
+	if a.transform != nil && a.transform.check != nil {
+		return a.transform.check(name, a.min, a.max)
+	}
+	return nil
+}
+
+// scale returns a function mapping the minimum of the axis's range to t0 and
+// the maximum to t1, or the other way around if the axis is reversed. name
+// identifies the axis in errors.
+func (a *axis) scale(name string, t0, t1 float64) (scaleFunc, error) {
+	if err := a.check(name); err != nil {
+		return nil, err
+	}
+	if a.reversed {
+		t0, t1 = t1, t0
+	}
+	f := a.transformed()
+	x0, x1 := f(a.min), f(a.max)
 	m := (t1 - t0) / (x1 - x0)
-	return func(a float64) float64 {
-		return (a-x0)*m + t0
+	return func(x float64) float64 {
+		return (f(x)-x0)*m + t0
+	}, nil
+}
+
+// ticks returns the positions of ticks along the axis. If the axis has
+// breaks, ticks are placed separately along each part of it.
+func (a *axis) ticks() []float64 {
+	if a.noTicks {
+		return nil
+	}
+	if len(a.customTicks) != 0 {
+		return a.customTicks
 	}
+	var locate TickLocator
+	switch {
+	case a.locator != nil:
+		locate = a.locator
+	case a.transform != nil:
+		locate = func(lo, hi float64) []float64 { return a.transform.ticks(lo, hi, a.tickCount, a.minor) }
+	case a.kind == valueTime:
+		locate = func(lo, hi float64) []float64 { return timeTicks(lo, hi, a.tickCount) }
+	case a.kind == valueDuration:
+		locate = func(lo, hi float64) []float64 { return durationTicks(lo, hi, a.tickCount) }
+	default:
+		locate = func(lo, hi float64) []float64 { return linearTicks(lo, hi, a.tickCount) }
+	}
+	if len(a.breaks) == 0 {
+		return locate(a.min, a.max)
+	}
+	var ticks []float64
+	for _, s := range a.segments() {
+		ticks = append(ticks, locate(s[0], s[1])...)
+	}
+	// Leave out ticks at the ends of breaks, whose labels would crowd
+	// together.
+	return slices.DeleteFunc(ticks, func(x float64) bool {
+		for _, b := range a.breaks {
+			if x >= b[0] && x <= b[1] {
+				return true
+			}
+		}
+		return false
+	})
 }
 
-func scaleLog(base int, x0, x1, t0, t1 float64) scaleFunc {
-	log := logFunc(base)
-	m := (t1 - t0) / (log(x1) - log(x0))
+// segments returns the parts of the axis's range between its breaks, in
+// order.
+func (a *axis) segments() [][2]float64 {
+	if len(a.breaks) == 0 {
+		return [][2]float64{{a.min, a.max}}
+	}
+	breaks := slices.Clone(a.breaks)
+	slices.SortFunc(breaks, func(a, b [2]float64) int {
+		return cmp.Compare(a[0], b[0])
+	})
+	var segs [][2]float64
+	lo := a.min
+	for _, b := range breaks {
+		if b[1] <= lo || b[0] >= a.max {
+			continue
+		}
+		if b[0] > lo {
+			segs = append(segs, [2]float64{lo, b[0]})
+		}
+		lo = b[1]
+	}
+	if lo < a.max {
+		segs = append(segs, [2]float64{lo, a.max})
+	}
+	return segs
+}
+
+// minorTicks returns the positions of minor ticks along the axis, between
+// the provided major ticks, or nil if the axis has no minor ticks.
+func (a *axis) minorTicks(major []float64) []float64 {
+	if !a.minor || a.noTicks || len(a.customTicks) != 0 {
+		return nil
+	}
+	var ticks []float64
+	if a.transform != nil && a.transform.minor != nil && a.locator == nil {
+		ticks = a.transform.minor(min(a.min, a.max), max(a.min, a.max))
+	} else {
+		// Subdivide each part of the axis separately, since their ticks
+		// may be spaced differently.
+		for _, s := range a.segments() {
+			lo, hi := min(s[0], s[1]), max(s[0], s[1])
+			var in []float64
+			for _, x := range major {
+				if x >= lo && x <= hi {
+					in = append(in, x)
+				}
+			}
+			ticks = append(ticks, subdivideTicks(in, lo, hi)...)
+		}
+	}
+	return slices.DeleteFunc(ticks, func(x float64) bool {
+		if slices.Contains(major, x) {
+			return true
+		}
+		for _, b := range a.breaks {
+			if x >= b[0] && x <= b[1] {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// subdivideTicks evenly divides the space between consecutive ticks, and
+// beyond the first and last out to lo and hi, into parts suited to the
+// spacing of the ticks.
+func subdivideTicks(major []float64, lo, hi float64) []float64 {
+	if len(major) < 2 {
+		return nil
+	}
+	parts := func(step float64) int {
+		// Steps of 2 are divided into 4 parts, and others into 5.
+		m := math.Abs(step) / math.Pow10(int(math.Floor(math.Log10(math.Abs(step)))))
+		if math.Abs(m-2) < 1e-6 {
+			return 4
+		}
+		return 5
+	}
+	var ticks []float64
+	for i := range len(major) - 1 {
+		x0, x1 := major[i], major[i+1]
+		n := parts(x1 - x0)
+		for k := 1; k < n; k++ {
+			ticks = append(ticks, x0+(x1-x0)*float64(k)/float64(n))
+		}
+	}
+	// Extend beyond the ends by the spacing of the nearest ticks.
+	first, last := major[0], major[len(major)-1]
+	// Multiplying, rather than accumulating, the spacing keeps the
+	// positions exact enough to match ticks and breaks.
+	if d := (major[1] - first) / float64(parts(major[1]-first)); d != 0 {
+		for k := 1.0; ; k++ {
+			x := first - k*d
+			if !(x >= min(lo, hi) && x <= max(lo, hi)) {
+				break
+			}
+			ticks = append(ticks, x)
+		}
+	}
+	if d := (last - major[len(major)-2]) / float64(parts(last-major[len(major)-2])); d != 0 {
+		for k := 1.0; ; k++ {
+			x := last + k*d
+			if !(x >= min(lo, hi) && x <= max(lo, hi)) {
+				break
+			}
+			ticks = append(ticks, x)
+		}
+	}
+	return ticks
+}
+
+// tickLabel returns the label for a tick at position x along the axis.
+func (a *axis) tickLabel(x float64) string {
+	if a.format != nil {
+		return a.format(x)
+	}
+	switch a.kind {
+	case valueTime:
+		return formatTime(x)
+	case valueDuration:
+		return FormatDuration(3)(x)
+	}
+	return strconv.FormatFloat(x, 'g', 3, 64)
+}
+
+type scaleFunc func(float64) float64
+
+func scaleLinear(x0, x1, t0, t1 float64) scaleFunc {
+	m := (t1 - t0) / (x1 - x0)
 	return func(a float64) float64 {
-		return (log(a)-log(x0))*m + t0
+		return (a-x0)*m + t0
 	}
 }
 
@@ -289,7 +820,11 @@ func linearTickSpec(start, stop float64, count int) (i1, i2 int, inc float64) {
 	return
 }
 
-func logTicks(base int, start, stop float64) []float64 {
+// logTicks places ticks at powers of base between start and stop, and
+// between them if there's room for roughly count ticks. If count is zero,
+// powers of a base of at least 10 are divided into quarters, and if it's
+// negative, there are only ticks at powers of base.
+func logTicks(base int, start, stop float64, count int) []float64 {
 	log := logFunc(base)
 	reverse := stop < start
 	if reverse {
@@ -298,15 +833,27 @@ func logTicks(base int, start, stop float64) []float64 {
 	lo := int(math.Floor(log(start)))
 	hi := int(math.Ceil(log(stop)))
 	majorTicks := hi - lo
-	var ticksPerMajor int
-	if base < 10 {
-		ticksPerMajor = 1
-	} else {
-		ticksPerMajor = 4
+	ticksPerMajor, majorStep := 1, 1
+	switch {
+	case count < 0:
+	case count == 0:
+		if base >= 10 {
+			ticksPerMajor = 4
+		}
+	case majorTicks >= count:
+		majorStep = (majorTicks + count - 1) / count
+	default:
+		// Divide each power of base evenly, into as many parts as fit.
+		for d := base - 1; d > 1; d-- {
+			if base%d == 0 && d*majorTicks <= count {
+				ticksPerMajor = d
+				break
+			}
+		}
 	}
 	ticks := make([]float64, 0, majorTicks*ticksPerMajor)
 loop:
-	for i := lo; i <= hi; i++ {
+	for i := lo; i <= hi; i += majorStep {
 		major := math.Pow(float64(base), float64(i))
 		for j := major / float64(ticksPerMajor); j <= major; j += major / float64(ticksPerMajor) {
 			if j < start {
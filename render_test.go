@@ -0,0 +1,415 @@
+package ggg
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLogTicks(t *testing.T) {
+	type test struct {
+		name        string
+		base        int
+		start, stop float64
+		count       int
+		want        []float64
+	}
+	for _, ts := range []test{
+		{
+			name: "Powers",
+			base: 10, start: 1, stop: 1000,
+			count: -1,
+			want:  []float64{1, 10, 100, 1000},
+		},
+		{
+			name: "PowersInside",
+			base: 10, start: 3, stop: 300,
+			count: -1,
+			want:  []float64{10, 100},
+		},
+		{
+			// With the default count, powers of 10 are divided into
+			// quarters.
+			name: "Default",
+			base: 10, start: 1, stop: 100,
+			count: 0,
+			want:  []float64{1, 2.5, 5, 7.5, 10, 25, 50, 75, 100},
+		},
+		{
+			name: "DefaultBase2",
+			base: 2, start: 1, stop: 16,
+			count: 0,
+			want:  []float64{1, 2, 4, 8, 16},
+		},
+		{
+			// Too many powers for the count, so only every other one is
+			// kept.
+			name: "FewerPowers",
+			base: 10, start: 1, stop: 1e6,
+			count: 3,
+			want:  []float64{1, 100, 1e4, 1e6},
+		},
+		{
+			// Room for more ticks than powers, so each power is divided
+			// into fifths.
+			name: "Divided",
+			base: 10, start: 1, stop: 100,
+			count: 10,
+			want:  []float64{1, 2, 4, 6, 8, 10, 20, 40, 60, 80, 100},
+		},
+		{
+			name: "Reversed",
+			base: 10, start: 1000, stop: 1,
+			count: -1,
+			want:  []float64{1000, 100, 10, 1},
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			got := logTicks(ts.base, ts.start, ts.stop, ts.count)
+			if !approxEqual(got, ts.want) {
+				t.Errorf("got %v, want %v", got, ts.want)
+			}
+		})
+	}
+}
+
+func TestLogMinorTicks(t *testing.T) {
+	type test struct {
+		name   string
+		base   int
+		unit   float64
+		lo, hi float64
+		want   []float64
+	}
+	for _, ts := range []test{
+		{
+			name: "Positive",
+			base: 10, unit: 1,
+			lo: 1, hi: 100,
+			want: []float64{2, 3, 4, 5, 6, 7, 8, 9, 20, 30, 40, 50, 60, 70, 80, 90},
+		},
+		{
+			name: "Small",
+			base: 10, unit: 1,
+			lo: 0.05, hi: 0.5,
+			want: []float64{0.05, 0.06, 0.07, 0.08, 0.09, 0.2, 0.3, 0.4, 0.5},
+		},
+		{
+			name: "Negative",
+			base: 10, unit: 1,
+			lo: -50, hi: -5,
+			want: []float64{-50, -40, -30, -20, -9, -8, -7, -6, -5},
+		},
+		{
+			// Minor ticks for a symmetric log scale are at multiples of
+			// its threshold.
+			name: "Symmetric",
+			base: 10, unit: 0.5,
+			lo: -5, hi: 5,
+			want: []float64{-4.5, -4, -3.5, -3, -2.5, -2, -1.5, -1, 1, 1.5, 2, 2.5, 3, 3.5, 4, 4.5},
+		},
+		{
+			// There are no multiples between powers of 2.
+			name: "Base2",
+			base: 2, unit: 1,
+			lo: 1, hi: 16,
+			want: nil,
+		},
+		{
+			name: "BadBase",
+			base: 1, unit: 1,
+			lo: 1, hi: 16,
+			want: nil,
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			got := logMinorTicks(ts.base, ts.unit, ts.lo, ts.hi)
+			if !approxEqual(got, ts.want) {
+				t.Errorf("got %v, want %v", got, ts.want)
+			}
+		})
+	}
+}
+
+func TestSubdivideTicks(t *testing.T) {
+	type test struct {
+		name   string
+		major  []float64
+		lo, hi float64
+		want   []float64
+	}
+	for _, ts := range []test{
+		{
+			name:  "Fifths",
+			major: []float64{0, 1, 2},
+			lo:    0, hi: 2,
+			want: []float64{0.2, 0.4, 0.6, 0.8, 1.2, 1.4, 1.6, 1.8},
+		},
+		{
+			// Steps of 2 are divided into quarters, and the minor ticks
+			// continue past the first and last ticks.
+			name:  "Quarters",
+			major: []float64{0, 2, 4},
+			lo:    -1, hi: 5,
+			want: []float64{0.5, 1, 1.5, 2.5, 3, 3.5, -0.5, -1, 4.5, 5},
+		},
+		{
+			name:  "Decreasing",
+			major: []float64{20, 10},
+			lo:    10, hi: 20,
+			want: []float64{18, 16, 14, 12},
+		},
+		{
+			name:  "OneTick",
+			major: []float64{1},
+			lo:    0, hi: 2,
+			want: nil,
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			got := subdivideTicks(ts.major, ts.lo, ts.hi)
+			if !approxEqual(got, ts.want) {
+				t.Errorf("got %v, want %v", got, ts.want)
+			}
+		})
+	}
+}
+
+func TestMinorTicks(t *testing.T) {
+	type test struct {
+		name     string
+		opts     []AxisOption
+		min, max float64
+		want     []float64
+	}
+	for _, ts := range []test{
+		{
+			name: "Off",
+			min:  0, max: 4,
+			want: nil,
+		},
+		{
+			name: "Linear",
+			opts: []AxisOption{MinorTicks()},
+			min:  0, max: 2,
+			want: []float64{0.1, 0.2, 0.3, 0.4, 0.6, 0.7, 0.8, 0.9, 1.1, 1.2, 1.3, 1.4, 1.6, 1.7, 1.8, 1.9},
+		},
+		{
+			// Each side of a break is divided separately, and minor ticks
+			// within it are left out.
+			name: "Break",
+			opts: []AxisOption{MinorTicks(), TickCount(2), Break(4, 6)},
+			min:  0, max: 10,
+			want: []float64{0.5, 1, 1.5, 2.5, 3, 3.5, 6.5, 7, 7.5, 8.5, 9, 9.5},
+		},
+		{
+			name: "Log",
+			opts: []AxisOption{LogScale(10), MinorTicks()},
+			min:  1, max: 100,
+			want: []float64{2, 3, 4, 5, 6, 7, 8, 9, 20, 30, 40, 50, 60, 70, 80, 90},
+		},
+		{
+			name: "CustomTicks",
+			opts: []AxisOption{MinorTicks(), Ticks(0, 2, 4)},
+			min:  0, max: 4,
+			want: nil,
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			a := axis{min: ts.min, max: ts.max}
+			for _, opt := range ts.opts {
+				opt(&a)
+			}
+			major := a.ticks()
+			got := a.minorTicks(major)
+			slices.Sort(got)
+			if !approxEqual(got, ts.want) {
+				t.Errorf("got %v, want %v", got, ts.want)
+			}
+			for _, x := range got {
+				if slices.Contains(major, x) {
+					t.Errorf("minor tick %v is also a major tick", x)
+				}
+			}
+		})
+	}
+}
+
+func TestTicks(t *testing.T) {
+	type test struct {
+		name string
+		opts []AxisOption
+		want []float64
+	}
+	for _, ts := range []test{
+		{
+			name: "Default",
+			want: []float64{0, 1, 2, 3, 4},
+		},
+		{
+			name: "Custom",
+			opts: []AxisOption{Ticks(0, 4)},
+			want: []float64{0, 4},
+		},
+		{
+			// No positions leave the default ticks in place.
+			name: "CustomEmpty",
+			opts: []AxisOption{Ticks()},
+			want: []float64{0, 1, 2, 3, 4},
+		},
+		{
+			name: "None",
+			opts: []AxisOption{NoTicks(), MinorTicks()},
+			want: nil,
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			a := axis{min: 0, max: 4}
+			for _, opt := range ts.opts {
+				opt(&a)
+			}
+			got := a.ticks()
+			if !slices.Equal(got, ts.want) {
+				t.Errorf("got ticks %v, want %v", got, ts.want)
+			}
+			if minor := a.minorTicks(got); ts.want == nil && minor != nil {
+				t.Errorf("got minor ticks %v, want none", minor)
+			}
+		})
+	}
+}
+
+func TestCheckAxes(t *testing.T) {
+	type test struct {
+		name string
+		plot *Plot
+		want string
+	}
+	for _, ts := range []test{
+		{
+			name: "Valid",
+			plot: pointsPlot(1, 10).Presentation(XAxis("", LogScale(10)), YAxis("", LogScale(10))),
+		},
+		{
+			// The range is checked before ticks are placed, which would
+			// panic for a log scale over zero.
+			name: "LogZero",
+			plot: pointsPlot(0, 10).Presentation(YAxis("", LogScale(10))),
+			want: "specified log scale, but domain of Y values is zero or negative",
+		},
+		{
+			name: "LogNegative",
+			plot: pointsPlot(-10, 10).Presentation(XAxis("", LogScale(2))),
+			want: "specified log scale, but domain of X values is zero or negative",
+		},
+		{
+			name: "LogY2",
+			plot: pointsPlot(1, 10).Layer(pointsPlot(0, 10).layers[0].AnyLayer, OnAxis(Y2)).Presentation(Y2Axis("", LogScale(10))),
+			want: "specified log scale, but domain of Y2 values is zero or negative",
+		},
+		{
+			name: "SymLogThreshold",
+			plot: pointsPlot(0, 10).Presentation(YAxis("", SymLogScale(10, 0))),
+			want: "threshold 0 isn't positive",
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			err := ts.plot.draw(newRasterCanvas(300, 200), testTheme(t), 300, 200)
+			switch {
+			case ts.want == "" && err != nil:
+				t.Errorf("got error %v, want none", err)
+			case ts.want != "" && (err == nil || !strings.Contains(err.Error(), ts.want)):
+				t.Errorf("got error %v, want one containing %q", err, ts.want)
+			}
+		})
+	}
+}
+
+func TestComputeRangesY2(t *testing.T) {
+	type test struct {
+		name          string
+		plot          *Plot
+		wantY, wantY2 [2]float64
+		wantX         [2]float64
+	}
+	for _, ts := range []test{
+		{
+			// Each Y axis covers only its own layers, and X covers both.
+			name:   "Independent",
+			plot:   pointsPlot(0, 10).Layer(pointsPlot(-5, 20).layers[0].AnyLayer, OnAxis(Y2)),
+			wantX:  [2]float64{-5, 20},
+			wantY:  [2]float64{0, 100},
+			wantY2: [2]float64{0, 400},
+		},
+		{
+			name:   "OnlyY2",
+			plot:   NewPlot().Layer(pointsPlot(1, 3).layers[0].AnyLayer, OnAxis(Y2)),
+			wantX:  [2]float64{1, 3},
+			wantY:  [2]float64{0, 0},
+			wantY2: [2]float64{1, 9},
+		},
+		{
+			name: "Limits",
+			plot: pointsPlot(0, 10).Layer(pointsPlot(0, 20).layers[0].AnyLayer, OnAxis(Y2)).
+				Presentation(Y2Axis("", Limits(-1, 1000))),
+			wantX:  [2]float64{0, 20},
+			wantY:  [2]float64{0, 100},
+			wantY2: [2]float64{-1, 1000},
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			p := ts.plot.prepared()
+			p.computeRanges()
+			for _, a := range []struct {
+				name string
+				got  *axis
+				want [2]float64
+			}{
+				{"X", &p.opts.x, ts.wantX},
+				{"Y", &p.opts.y, ts.wantY},
+				{"Y2", &p.opts.y2, ts.wantY2},
+			} {
+				if a.got.min != a.want[0] || a.got.max != a.want[1] {
+					t.Errorf("got %s range [%v, %v], want %v", a.name, a.got.min, a.got.max, a.want)
+				}
+			}
+		})
+	}
+}
+
+func TestAxisBreaks(t *testing.T) {
+	// paths counts the paths drawn for p.
+	paths := func(t *testing.T, p *Plot) int {
+		var buf bytes.Buffer
+		sc := newSVGCanvas(&buf, 300, 200)
+		if err := p.draw(sc, testTheme(t), 300, 200); err != nil {
+			t.Fatal(err)
+		}
+		if err := sc.finish(); err != nil {
+			t.Fatal(err)
+		}
+		return strings.Count(buf.String(), "<path")
+	}
+	plot := func(opts ...PresentationOption) *Plot {
+		return pointsPlot(0, 10).Layer(pointsPlot(0, 20).layers[0].AnyLayer, OnAxis(Y2)).Presentation(opts...)
+	}
+	base := paths(t, plot())
+
+	type test struct {
+		name string
+		opt  PresentationOption
+	}
+	for _, ts := range []test{
+		{"X", XAxis("", Break(12, 14))},
+		{"Y", YAxis("", Break(40, 60))},
+		{"Y2", Y2Axis("", Break(100, 200))},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			// Each break covers the axis line, and draws a mark over it.
+			if got := paths(t, plot(ts.opt)) - base; got != 2 {
+				t.Errorf("break drew %d paths, want 2", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package ggg
+
+import (
+	"errors"
+	"io"
+
+	"github.com/mknyszek/ggg/termimg"
+)
+
+// Show renders the plot at the default size and writes it to w as an inline
+// image for the terminal, using the Kitty graphics protocol or Sixel,
+// whichever the environment indicates is supported.
+func (p *Plot) Show(w io.Writer, theme string) error {
+	proto := termimg.Detect()
+	if proto == termimg.None {
+		return errors.New("terminal doesn't support inline images")
+	}
+	img, err := p.Render(theme, defaultWidth, defaultHeight)
+	if err != nil {
+		return err
+	}
+	if err := termimg.Encode(w, img, proto); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
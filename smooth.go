@@ -0,0 +1,241 @@
+package ggg
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// Smooth is a layer that fits a curve through the points of each series,
+// such as a trend line, and draws it with the Line geom. Rows are split
+// into series by the Geom's color and size.
+type Smooth[X Value, Y Scalar] struct {
+	Data   *Dataset
+	X      Column[X]
+	Y      Column[Y]
+	Method Smoother
+	Geom   *Geom
+
+	// Confidence, if not zero, is the level of a confidence interval for
+	// the curve, such as 0.95, which is shaded around it.
+	Confidence float64
+
+	// filter, if not nil, restricts the rows that are fitted.
+	filter Filter
+
+	// prepared is set if series holds the fitted curves, as computed by
+	// prepare.
+	prepared bool
+	series   []*smoothSeries
+}
+
+// Fit is a curve fitted to one series of a Smooth layer.
+type Fit struct {
+	// Series is the legend label of the series, if it has one.
+	Series string
+
+	// Coefficients are those of the fitted polynomial, where
+	// Coefficients[i] is the coefficient of x^i, for X values positioned
+	// as along the axis. They're nil if the curve isn't a polynomial.
+	Coefficients []float64
+
+	// N is the number of points the curve was fitted to.
+	N int
+
+	// Predict returns the value of the curve at x.
+	Predict func(x float64) float64
+}
+
+// smoothPoints is the number of points at which each curve is evaluated.
+const smoothPoints = 100
+
+// smoothSeries is the curve fitted to one series.
+type smoothSeries struct {
+	row        int // A representative row, for the series' color and size.
+	xs, ys     []float64
+	fit        *fitted
+	curve      []float64 // X values at which the curve is evaluated.
+	fy, lo, hi []float64
+}
+
+// fits splits the layer's rows into series, and fits a curve to each.
+// Series with too few distinct points to fit are left out.
+func (s *Smooth[X, Y]) fits() []*smoothSeries {
+	if s.prepared {
+		return s.series
+	}
+	if s.Geom == nil || s.Data == nil || !s.X.Valid() || !s.Y.Valid() {
+		return nil
+	}
+	smap := make(map[any]*smoothSeries)
+	var ss []*smoothSeries
+	for row := range filteredRows(s.Data, s.filter) {
+		x, y := toFloat(s.X.Get(s.Data, row)), toFloat(s.Y.Get(s.Data, row))
+		if math.IsNaN(x) || math.IsNaN(y) {
+			continue
+		}
+		key := s.Geom.grouping(s.Data, row)
+		ser, ok := smap[key]
+		if !ok {
+			ser = &smoothSeries{row: row}
+			smap[key] = ser
+			ss = append(ss, ser)
+		}
+		ser.xs = append(ser.xs, x)
+		ser.ys = append(ser.ys, y)
+	}
+	var fitted []*smoothSeries
+	for _, ser := range ss {
+		if len(ser.xs) < 2 {
+			continue
+		}
+		ser.fit = s.Method.apply(ser.xs, ser.ys)
+		if ser.fit == nil {
+			continue
+		}
+		lo, hi := slices.Min(ser.xs), slices.Max(ser.xs)
+		for i := range smoothPoints {
+			x := lo + (hi-lo)*float64(i)/(smoothPoints-1)
+			y, se := ser.fit.predict(x)
+			ser.curve = append(ser.curve, x)
+			ser.fy = append(ser.fy, y)
+			if s.Confidence != 0 {
+				l, h := ser.fit.interval(y, se, s.Confidence)
+				ser.lo = append(ser.lo, l)
+				ser.hi = append(ser.hi, h)
+			}
+		}
+		fitted = append(fitted, ser)
+	}
+	return fitted
+}
+
+func (s *Smooth[X, Y]) prepare() AnyLayer {
+	c := *s
+	c.series = s.fits()
+	c.prepared = true
+	return &c
+}
+
+// Fits returns the curves fitted to each series of the layer.
+func (s *Smooth[X, Y]) Fits() []Fit {
+	var fits []Fit
+	for _, ser := range s.fits() {
+		fits = append(fits, Fit{
+			Series:       s.Geom.seriesLabel(s.Data, ser.row),
+			Coefficients: ser.fit.coef,
+			N:            len(ser.xs),
+			Predict: func(x float64) float64 {
+				y, _ := ser.fit.predict(x)
+				return y
+			},
+		})
+	}
+	return fits
+}
+
+func (s *Smooth[X, Y]) xRange() (lo, hi float64) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+	for _, ser := range s.fits() {
+		lo, hi = min(lo, ser.curve[0]), max(hi, ser.curve[len(ser.curve)-1])
+	}
+	return lo, hi
+}
+
+func (s *Smooth[X, Y]) yRange() (lo, hi float64) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+	for _, ser := range s.fits() {
+		for _, ys := range [][]float64{ser.fy, ser.lo, ser.hi} {
+			if len(ys) != 0 {
+				lo, hi = min(lo, slices.Min(ys)), max(hi, slices.Max(ys))
+			}
+		}
+	}
+	return lo, hi
+}
+
+func (s *Smooth[X, Y]) xKind() valueKind {
+	return kindOf[X]()
+}
+
+func (s *Smooth[X, Y]) yKind() valueKind {
+	return kindOf[Y]()
+}
+
+func (s *Smooth[X, Y]) legend(theme *Theme) []legendEntry {
+	if s.Geom == nil || s.Data == nil {
+		return nil
+	}
+	return s.Geom.legend(s.Data, theme)
+}
+
+func (s *Smooth[X, Y]) dataset() *Dataset {
+	return s.Data
+}
+
+// where returns a copy of the layer that only fits rows accepted by f.
+func (s *Smooth[X, Y]) where(f Filter) AnyLayer {
+	c := *s
+	if c.filter != nil {
+		f = And(c.filter, f)
+	}
+	c.filter = f
+	c.prepared, c.series = false, nil
+	return &c
+}
+
+func (s *Smooth[X, Y]) render(c canvas, theme *Theme, xScale, yScale scaleFunc, scaleFactor float64) error {
+	if s.Geom == nil || s.Geom.kind != kindLine {
+		return fmt.Errorf("smooth can only be drawn with the Line geom")
+	}
+	if s.Data == nil {
+		return fmt.Errorf("no intended dataset specified for smooth")
+	}
+	if !s.X.Valid() {
+		return fmt.Errorf("no initialized X column for smooth")
+	}
+	if !s.Y.Valid() {
+		return fmt.Errorf("no initialized Y column for smooth")
+	}
+
+	rec, _ := c.(pointRecorder)
+	for _, ser := range s.fits() {
+		label := s.Geom.seriesLabel(s.Data, ser.row)
+		beginGroup(c, "series", label)
+		col := s.Geom.color.scale(s.Data, ser.row, theme)
+		if len(ser.lo) != 0 {
+			c.setColor(faint(col))
+			c.moveTo(xScale(ser.curve[0]), yScale(ser.lo[0]))
+			for i := range ser.curve {
+				c.lineTo(xScale(ser.curve[i]), yScale(ser.lo[i]))
+			}
+			for i := len(ser.curve) - 1; i >= 0; i-- {
+				c.lineTo(xScale(ser.curve[i]), yScale(ser.hi[i]))
+			}
+			c.closePath()
+			c.fill()
+		}
+		c.setColor(col)
+		c.moveTo(xScale(ser.curve[0]), yScale(ser.fy[0]))
+		for i := range ser.curve {
+			c.lineTo(xScale(ser.curve[i]), yScale(ser.fy[i]))
+		}
+		c.setLineWidth(scaleFactor * s.Geom.size.scale(s.Data, ser.row, theme))
+		c.stroke()
+		if rec != nil {
+			for i, x := range ser.curve {
+				fields := []field{
+					{s.X.Name(), strconv.FormatFloat(x, 'g', 6, 64)},
+					{s.Y.Name(), strconv.FormatFloat(ser.fy[i], 'g', 6, 64)},
+				}
+				if len(ser.lo) != 0 {
+					fields = append(fields, field{"interval", strconv.FormatFloat(ser.lo[i], 'g', 6, 64) + " – " + strconv.FormatFloat(ser.hi[i], 'g', 6, 64)})
+				}
+				rec.recordPoint(label, xScale(x), yScale(ser.fy[i]), fields)
+			}
+		}
+		endGroup(c)
+	}
+	return nil
+}
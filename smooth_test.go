@@ -0,0 +1,115 @@
+package ggg
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestSmoothFits(t *testing.T) {
+	colX := NewColumn[float64]("x")
+	colY := NewColumn[float64]("y")
+	colS := NewColumn[string]("s")
+	d := Empty()
+	d.AddColumn(colX)
+	d.AddColumn(colY)
+	d.AddColumn(colS)
+	for i := range 20 {
+		x := float64(i)
+		for row := range d.Grow(2) {
+			colX.Set(d, row, x)
+			colS.Set(d, row, []string{"line", "parabola"}[row%2])
+			if row%2 == 0 {
+				colY.Set(d, row, 3-2*x)
+			} else {
+				colY.Set(d, row, 1+x-0.5*x*x)
+			}
+		}
+	}
+	type test struct {
+		name   string
+		method Smoother
+		// want holds, for each series, its coefficients, or nil if the
+		// curve isn't a polynomial.
+		want [][]float64
+		// exact is set if the curve fits the parabola exactly.
+		exact bool
+	}
+	for _, ts := range []test{
+		{
+			name:   "Linear",
+			method: Smoother{},
+			want:   [][]float64{{3, -2}, {29.5, -8.5}},
+		},
+		{
+			name:   "Quadratic",
+			method: Polynomial(2),
+			want:   [][]float64{{3, -2, 0}, {1, 1, -0.5}},
+			exact:  true,
+		},
+		{
+			name:   "Loess",
+			method: Loess(0.5),
+			want:   [][]float64{nil, nil},
+			// Local quadratics reproduce a quadratic.
+			exact: true,
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			s := &Smooth[float64, float64]{Data: d, X: colX, Y: colY, Method: ts.method, Geom: Line(NiceColors(colS), Constant(1.0))}
+			fits := s.Fits()
+			if len(fits) != len(ts.want) {
+				t.Fatalf("got %d fits, want %d", len(fits), len(ts.want))
+			}
+			for i, f := range fits {
+				if f.N != 20 {
+					t.Errorf("fit %d: got %d points, want 20", i, f.N)
+				}
+				if !approxEqual(f.Coefficients, ts.want[i]) {
+					t.Errorf("fit %d: got coefficients %v, want %v", i, f.Coefficients, ts.want[i])
+				}
+			}
+			if ts.exact {
+				for _, x := range []float64{0, 2.5, 19} {
+					if got, want := fits[1].Predict(x), 1+x-0.5*x*x; math.Abs(got-want) > 1e-6 {
+						t.Errorf("got %v at %v, want %v", got, x, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSmoothRange(t *testing.T) {
+	colX := NewColumn[float64]("x")
+	d := Empty()
+	d.AddColumn(colX)
+	for row := range d.Grow(10) {
+		colX.Set(d, row, float64(row))
+	}
+	s := &Smooth[float64, float64]{Data: d, X: colX, Y: colX, Geom: Line(Constant[color.Color](nil), Constant(1.0))}
+	if lo, hi := s.xRange(); lo != 0 || hi != 9 {
+		t.Errorf("got X range [%v, %v], want [0, 9]", lo, hi)
+	}
+	f := s.where(GreaterThanOrEqualTo(colX, 5))
+	if lo, hi := f.xRange(); lo != 5 || hi != 9 {
+		t.Errorf("got filtered X range [%v, %v], want [5, 9]", lo, hi)
+	}
+	if lo, hi := f.yRange(); math.Abs(lo-5) > 1e-9 || math.Abs(hi-9) > 1e-9 {
+		t.Errorf("got filtered Y range [%v, %v], want [5, 9]", lo, hi)
+	}
+}
+
+// approxEqual reports whether the values in a and b are all within 1e-9 of each
+// other.
+func approxEqual(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > 1e-9*math.Max(1, math.Abs(b[i])) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,15 +1,22 @@
 package ggg
 
 import (
+	"cmp"
 	"fmt"
 	"iter"
+	"math"
+	"slices"
 
+	"github.com/aclements/go-moremath/stats"
 	"golang.org/x/perf/benchmath"
 )
 
-type Statistic[T Scalar] struct {
+type Statistic[T Value] struct {
 	f    func(iter.Seq[T], []float64)
 	dims int
+
+	// names, if not nil, names each dimension of the result.
+	names []string
 }
 
 func (s Statistic[T]) Valid() bool {
@@ -33,7 +40,7 @@ func (s Statistic[T]) ApplyInto(values iter.Seq[T], result []float64) {
 	s.f(values, result)
 }
 
-func Count[T Scalar]() Statistic[T] {
+func Count[T Value]() Statistic[T] {
 	return Statistic[T]{
 		f: func(seq iter.Seq[T], result []float64) {
 			var n int
@@ -74,6 +81,9 @@ func Mean[T Scalar]() Statistic[T] {
 	}
 }
 
+// Confidence summarizes values by a confidence interval for their median,
+// which makes no assumption about their distribution. The result has 2
+// dimensions: the lower and upper bounds of the interval.
 func Confidence[T Scalar](confidence float64) Statistic[T] {
 	return Statistic[T]{
 		f: func(seq iter.Seq[T], result []float64) {
@@ -85,10 +95,13 @@ func Confidence[T Scalar](confidence float64) Statistic[T] {
 			sum := benchmath.AssumeNothing.Summary(samp, confidence)
 			result[0], result[1] = sum.Lo, sum.Hi
 		},
-		dims: 1,
+		dims: 2,
 	}
 }
 
+// ConfidenceNormal summarizes values by a confidence interval for their
+// mean, assuming they're normally distributed. The result has 2 dimensions:
+// the lower and upper bounds of the interval.
 func ConfidenceNormal[T Scalar](confidence float64) Statistic[T] {
 	return Statistic[T]{
 		f: func(seq iter.Seq[T], result []float64) {
@@ -100,6 +113,430 @@ func ConfidenceNormal[T Scalar](confidence float64) Statistic[T] {
 			sum := benchmath.AssumeNormal.Summary(samp, confidence)
 			result[0], result[1] = sum.Lo, sum.Hi
 		},
-		dims: 1,
+		dims: 2,
+	}
+}
+
+// Quantiles summarizes values for a box plot. The result has 5 dimensions:
+// the lower whisker, the first quartile, the median, the third quartile and
+// the upper whisker. The whiskers are the most extreme values within 1.5
+// times the interquartile range of the quartiles, which are the minimum and
+// maximum unless there are outliers.
+func Quantiles[T Scalar]() Statistic[T] {
+	return Statistic[T]{
+		f: func(seq iter.Seq[T], result []float64) {
+			var f []float64
+			for v := range seq {
+				f = append(f, float64(v))
+			}
+			slices.Sort(f)
+			q1, q3 := quantile(f, 0.25), quantile(f, 0.75)
+			lo, hi := whiskers(f, q1, q3)
+			result[0], result[1], result[2], result[3], result[4] = lo, q1, quantile(f, 0.5), q3, hi
+		},
+		dims:  5,
+		names: []string{"low", "q1", "median", "q3", "high"},
+	}
+}
+
+// whiskers returns the most extreme of the sorted values within 1.5 times
+// the interquartile range of the quartiles q1 and q3.
+func whiskers(sorted []float64, q1, q3 float64) (lo, hi float64) {
+	fence := 1.5 * (q3 - q1)
+	i, _ := slices.BinarySearch(sorted, q1-fence)
+	j, found := slices.BinarySearch(sorted, q3+fence)
+	for found && j+1 < len(sorted) && sorted[j+1] == q3+fence {
+		j++
+	}
+	if !found {
+		j--
+	}
+	return sorted[i], sorted[j]
+}
+
+// Smoother fits a curve through points, for Smooth. The zero Smoother fits
+// a straight line.
+type Smoother struct {
+	fit func(xs, ys []float64) *fitted
+}
+
+// fitted is a curve fitted through some points.
+type fitted struct {
+	// predict returns the value of the curve at x, and its standard error.
+	predict func(x float64) (y, se float64)
+
+	// coef are the coefficients of the polynomial, where coef[i] is that of
+	// x^i, or nil if the curve isn't a polynomial.
+	coef []float64
+
+	// dof is the residual degrees of freedom, for confidence intervals.
+	dof float64
+}
+
+func (s Smoother) apply(xs, ys []float64) *fitted {
+	if s.fit == nil {
+		return Linear().fit(xs, ys)
+	}
+	return s.fit(xs, ys)
+}
+
+// Linear fits a straight line by least squares.
+func Linear() Smoother {
+	return Polynomial(1)
+}
+
+// Polynomial fits a polynomial of the provided degree by least squares.
+func Polynomial(degree int) Smoother {
+	return Smoother{func(xs, ys []float64) *fitted {
+		m, sd := standardize(xs)
+		us := make([]float64, len(xs))
+		for i, x := range xs {
+			us[i] = (x - m) / sd
+		}
+		p := min(degree+1, len(xs))
+		coef, cov := weightedPoly(us, ys, nil, p-1)
+		if coef == nil {
+			return nil
+		}
+		var rss float64
+		for i, u := range us {
+			r := ys[i] - evalPoly(coef, u)
+			rss += r * r
+		}
+		dof := float64(len(xs) - p)
+		s2 := rss / max(dof, 1)
+		return &fitted{
+			predict: func(x float64) (y, se float64) {
+				u := (x - m) / sd
+				v := powers(u, p)
+				var q float64
+				for i := range v {
+					for j := range v {
+						q += v[i] * cov[i][j] * v[j]
+					}
+				}
+				return evalPoly(coef, u), math.Sqrt(s2 * max(q, 0))
+			},
+			coef: unstandardize(coef, m, sd),
+			dof:  dof,
+		}
+	}}
+}
+
+// Loess fits a smooth curve by locally weighted regression. At each point,
+// a quadratic is fitted to the nearest span fraction of the points,
+// weighting them by their distance. Smaller spans follow the points more
+// closely; spans between 0.5 and 0.75 are typical.
+func Loess(span float64) Smoother {
+	return Smoother{func(xs, ys []float64) *fitted {
+		m, sd := standardize(xs)
+
+		// Sort the points, so that the nearest points to any position
+		// are a window of them.
+		us := make([]float64, len(xs))
+		order := make([]int, len(xs))
+		for i, x := range xs {
+			us[i] = (x - m) / sd
+			order[i] = i
+		}
+		slices.SortFunc(order, func(i, j int) int {
+			return cmp.Compare(us[i], us[j])
+		})
+		sus, sys := make([]float64, len(xs)), make([]float64, len(xs))
+		for i, j := range order {
+			sus[i], sys[i] = us[j], ys[j]
+		}
+		us, ys = sus, sys
+
+		q := min(max(int(math.Ceil(span*float64(len(xs)))), 3), len(xs))
+		p := min(3, q)
+
+		// local fits a curve around u to the window of the nearest points,
+		// us[start:start+len(ws)], weighted by ws. The coefficients are nil
+		// if the fit is underdetermined.
+		local := func(u float64) (start int, ws, coef []float64, cov [][]float64) {
+			// Grow the window of the nearest q points out from u.
+			lo, _ := slices.BinarySearch(us, u)
+			hi := lo
+			for hi-lo < q {
+				if hi == len(us) || lo > 0 && u-us[lo-1] <= us[hi]-u {
+					lo--
+				} else {
+					hi++
+				}
+			}
+			h := max(u-us[lo], us[hi-1]-u)
+			// Include the nearest q points, and not the next one.
+			next := math.Inf(1)
+			if lo > 0 {
+				next = u - us[lo-1]
+			}
+			if hi < len(us) {
+				next = min(next, us[hi]-u)
+			}
+			if !math.IsInf(next, 0) {
+				h = (h + next) / 2
+			}
+			h = max(h, 1e-12)
+
+			ws = make([]float64, hi-lo)
+			for i, x := range us[lo:hi] {
+				if d := math.Abs(x - u); d < h {
+					t := d / h
+					t = 1 - t*t*t
+					ws[i] = t * t * t
+				}
+			}
+			coef, cov = weightedPoly(us[lo:hi], ys[lo:hi], ws, p-1)
+			return lo, ws, coef, cov
+		}
+
+		// influence returns the weight of the point us[i], which has weight
+		// w in the local fit at u with covariance cov, in the fitted value.
+		// The fitted value is the sum of the points weighted this way.
+		influence := func(u float64, i int, w float64, cov [][]float64) float64 {
+			if w == 0 {
+				return 0
+			}
+			v, xi := powers(u, p), powers(us[i], p)
+			var a float64
+			for j := range v {
+				for k := range xi {
+					a += v[j] * cov[j][k] * xi[k]
+				}
+			}
+			return a * w
+		}
+
+		// Estimate the variance of the points about the curve.
+		var rss, trace float64
+		for i, u := range us {
+			start, ws, coef, cov := local(u)
+			if coef == nil {
+				return nil
+			}
+			y := evalPoly(coef, u)
+			rss += (ys[i] - y) * (ys[i] - y)
+			trace += influence(u, i, ws[i-start], cov)
+		}
+		dof := float64(len(xs)) - trace
+		s2 := rss / max(dof, 1)
+		return &fitted{
+			predict: func(x float64) (y, se float64) {
+				u := (x - m) / sd
+				start, ws, coef, cov := local(u)
+				if coef == nil {
+					return math.NaN(), 0
+				}
+				var sum float64
+				for i, w := range ws {
+					l := influence(u, start+i, w, cov)
+					sum += l * l
+				}
+				return evalPoly(coef, u), math.Sqrt(s2 * sum)
+			},
+			dof: dof,
+		}
+	}}
+}
+
+// interval returns the confidence interval at the provided level around
+// the value y with standard error se.
+func (f *fitted) interval(y, se, level float64) (lo, hi float64) {
+	t := stats.InvCDF(stats.TDist{V: max(f.dof, 1)})((1 + level) / 2)
+	return y - t*se, y + t*se
+}
+
+// standardize returns the mean and standard deviation of xs, for fitting
+// curves in well-conditioned coordinates. The standard deviation is 1 if
+// all the values are the same.
+func standardize(xs []float64) (mean, sd float64) {
+	mean, sd = stats.Mean(xs), stats.StdDev(xs)
+	if sd == 0 || math.IsNaN(sd) {
+		sd = 1
+	}
+	return mean, sd
+}
+
+// weightedPoly fits a polynomial of the provided degree to the points by
+// weighted least squares, with all weights 1 if ws is nil. It returns the
+// coefficients and the inverse of the normal matrix, or nil if the fit is
+// underdetermined.
+func weightedPoly(xs, ys, ws []float64, degree int) (coef []float64, inv [][]float64) {
+	p := degree + 1
+	a := make([][]float64, p)
+	for i := range a {
+		a[i] = make([]float64, p)
+	}
+	b := make([]float64, p)
+	v := make([]float64, p)
+	for i, x := range xs {
+		w := 1.0
+		if ws != nil {
+			w = ws[i]
+		}
+		if w == 0 {
+			continue
+		}
+		t := 1.0
+		for j := range v {
+			v[j] = t
+			t *= x
+		}
+		for j := range v {
+			for k := range v {
+				a[j][k] += w * v[j] * v[k]
+			}
+			b[j] += w * v[j] * ys[i]
+		}
+	}
+	inv = invert(a)
+	if inv == nil {
+		return nil, nil
+	}
+	coef = make([]float64, p)
+	for j := range coef {
+		for k := range b {
+			coef[j] += inv[j][k] * b[k]
+		}
+	}
+	return coef, inv
+}
+
+// invert returns the inverse of the square matrix a, or nil if it's
+// singular. It modifies a.
+func invert(a [][]float64) [][]float64 {
+	n := len(a)
+	inv := make([][]float64, n)
+	for i := range inv {
+		inv[i] = make([]float64, n)
+		inv[i][i] = 1
+	}
+	for col := range n {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return nil
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		inv[col], inv[pivot] = inv[pivot], inv[col]
+		d := a[col][col]
+		for k := range n {
+			a[col][k] /= d
+			inv[col][k] /= d
+		}
+		for row := range n {
+			if row == col || a[row][col] == 0 {
+				continue
+			}
+			f := a[row][col]
+			for k := range n {
+				a[row][k] -= f * a[col][k]
+				inv[row][k] -= f * inv[col][k]
+			}
+		}
+	}
+	return inv
+}
+
+// powers returns 1, x, x², and so on, up to n terms.
+func powers(x float64, n int) []float64 {
+	v := make([]float64, n)
+	t := 1.0
+	for i := range v {
+		v[i] = t
+		t *= x
+	}
+	return v
+}
+
+// evalPoly evaluates the polynomial with coefficients coef at x.
+func evalPoly(coef []float64, x float64) float64 {
+	var y float64
+	for i := len(coef) - 1; i >= 0; i-- {
+		y = y*x + coef[i]
+	}
+	return y
+}
+
+// unstandardize converts the coefficients of a polynomial in (x-m)/sd to
+// those of the same polynomial in x.
+func unstandardize(coef []float64, m, sd float64) []float64 {
+	out := make([]float64, len(coef))
+	for k, a := range coef {
+		// Expand a·((x-m)/sd)^k binomially.
+		a /= math.Pow(sd, float64(k))
+		binom := 1.0
+		for j := 0; j <= k; j++ {
+			out[j] += a * binom * math.Pow(-m, float64(k-j))
+			binom = binom * float64(k-j) / float64(j+1)
+		}
+	}
+	return out
+}
+
+// Correlation returns the Pearson correlation coefficient of the values of
+// x and y in each row of d, which measures how close they are to having a
+// linear relationship. It's between -1 and 1, or NaN if either column is
+// constant.
+func Correlation[X, Y Scalar](d *Dataset, x Column[X], y Column[Y]) float64 {
+	var xs, ys []float64
+	for row := range d.Rows() {
+		xs = append(xs, toFloat(x.Get(d, row)))
+		ys = append(ys, toFloat(y.Get(d, row)))
+	}
+	return pearson(xs, ys)
+}
+
+// RankCorrelation returns the Spearman rank correlation coefficient of the
+// values of x and y in each row of d, which measures how close they are to
+// having a monotonic relationship. Unlike Correlation, it isn't swayed by
+// outliers. It's between -1 and 1, or NaN if either column is constant.
+func RankCorrelation[X, Y Scalar](d *Dataset, x Column[X], y Column[Y]) float64 {
+	var xs, ys []float64
+	for row := range d.Rows() {
+		xs = append(xs, toFloat(x.Get(d, row)))
+		ys = append(ys, toFloat(y.Get(d, row)))
+	}
+	return pearson(ranks(xs), ranks(ys))
+}
+
+func pearson(xs, ys []float64) float64 {
+	mx, my := stats.Mean(xs), stats.Mean(ys)
+	var sxy, sxx, syy float64
+	for i := range xs {
+		dx, dy := xs[i]-mx, ys[i]-my
+		sxy += dx * dy
+		sxx += dx * dx
+		syy += dy * dy
+	}
+	return sxy / math.Sqrt(sxx*syy)
+}
+
+// ranks returns the rank of each of vs, starting from 1. Tied values get
+// the mean of their ranks.
+func ranks(vs []float64) []float64 {
+	order := make([]int, len(vs))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortFunc(order, func(a, b int) int {
+		return cmp.Compare(vs[a], vs[b])
+	})
+	r := make([]float64, len(vs))
+	for i := 0; i < len(order); {
+		j := i + 1
+		for j < len(order) && vs[order[j]] == vs[order[i]] {
+			j++
+		}
+		for _, k := range order[i:j] {
+			r[k] = float64(i+j+1) / 2
+		}
+		i = j
 	}
+	return r
 }
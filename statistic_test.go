@@ -0,0 +1,161 @@
+package ggg
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestQuantiles(t *testing.T) {
+	type test struct {
+		name   string
+		values []float64
+		want   []float64
+	}
+	for _, ts := range []test{
+		{
+			name:   "One",
+			values: []float64{7},
+			want:   []float64{7, 7, 7, 7, 7},
+		},
+		{
+			name:   "Unsorted",
+			values: []float64{5, 1, 4, 2, 3},
+			want:   []float64{1, 2, 3, 4, 5},
+		},
+		{
+			name:   "Interpolated",
+			values: []float64{1, 2, 3, 4},
+			want:   []float64{1, 1.75, 2.5, 3.25, 4},
+		},
+		{
+			name:   "Outliers",
+			values: []float64{-100, 1, 2, 3, 4, 5, 100},
+			want:   []float64{1, 1.5, 3, 4.5, 5},
+		},
+		{
+			// Values on the fences are within the whiskers.
+			name:   "OnFence",
+			values: []float64{-1.5, 0, 0, 0.5, 1, 1, 2.5},
+			want:   []float64{-1.5, 0, 0.5, 1, 2.5},
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			got := Quantiles[float64]().Apply(slices.Values(ts.values))
+			if !slices.Equal(got, ts.want) {
+				t.Errorf("got %v, want %v", got, ts.want)
+			}
+		})
+	}
+}
+
+func TestConfidence(t *testing.T) {
+	type test struct {
+		name string
+		stat Statistic[float64]
+	}
+	values := []float64{4, 8, 1, 9, 3, 7, 5, 2, 6, 5}
+	for _, ts := range []test{
+		{"Nothing", Confidence[float64](0.95)},
+		{"Normal", ConfidenceNormal[float64](0.95)},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			// Both bounds of the interval are stored in the result, which
+			// has room for them.
+			if got := ts.stat.Dimensions(); got != 2 {
+				t.Fatalf("got %d dimensions, want 2", got)
+			}
+			got := make([]float64, 2)
+			ts.stat.ApplyInto(slices.Values(values), got)
+			if !(got[0] < 5 && got[1] > 5) {
+				t.Errorf("got interval %v, want one around 5", got)
+			}
+		})
+	}
+}
+
+func TestRanks(t *testing.T) {
+	type test struct {
+		name   string
+		values []float64
+		want   []float64
+	}
+	for _, ts := range []test{
+		{name: "Empty", values: nil, want: []float64{}},
+		{name: "Sorted", values: []float64{1, 2, 3}, want: []float64{1, 2, 3}},
+		{name: "Unsorted", values: []float64{30, 10, 20}, want: []float64{3, 1, 2}},
+		{name: "Ties", values: []float64{5, 1, 5, 5, 0}, want: []float64{4, 2, 4, 4, 1}},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			if got := ranks(ts.values); !slices.Equal(got, ts.want) {
+				t.Errorf("got ranks %v, want %v", got, ts.want)
+			}
+		})
+	}
+}
+
+func TestCorrelation(t *testing.T) {
+	type test struct {
+		name        string
+		xs, ys      []float64
+		pearson     float64
+		rankPearson float64
+	}
+	for _, ts := range []test{
+		{
+			name:        "Linear",
+			xs:          []float64{1, 2, 3, 4},
+			ys:          []float64{3, 5, 7, 9},
+			pearson:     1,
+			rankPearson: 1,
+		},
+		{
+			name:        "Inverse",
+			xs:          []float64{1, 2, 3, 4},
+			ys:          []float64{4, 3, 2, 1},
+			pearson:     -1,
+			rankPearson: -1,
+		},
+		{
+			// Monotonic but not linear, so only the ranks are perfectly
+			// correlated.
+			name:        "Monotonic",
+			xs:          []float64{1, 2, 3, 4, 5},
+			ys:          []float64{1, 2, 3, 4, 1000},
+			pearson:     0.7088767522789017,
+			rankPearson: 1,
+		},
+		{
+			name:        "Constant",
+			xs:          []float64{1, 2, 3},
+			ys:          []float64{2, 2, 2},
+			pearson:     math.NaN(),
+			rankPearson: math.NaN(),
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			colX, colY := NewColumn[float64]("x"), NewColumn[float64]("y")
+			d := Empty()
+			d.AddColumn(colX)
+			d.AddColumn(colY)
+			for i := range ts.xs {
+				for row := range d.Grow(1) {
+					colX.Set(d, row, ts.xs[i])
+					colY.Set(d, row, ts.ys[i])
+				}
+			}
+			for _, c := range []struct {
+				name string
+				got  float64
+				want float64
+			}{
+				{"Correlation", Correlation(d, colX, colY), ts.pearson},
+				{"RankCorrelation", RankCorrelation(d, colX, colY), ts.rankPearson},
+			} {
+				if math.IsNaN(c.want) != math.IsNaN(c.got) || math.Abs(c.got-c.want) > 1e-9 {
+					t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+				}
+			}
+		})
+	}
+}
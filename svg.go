@@ -0,0 +1,259 @@
+package ggg
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/golang/freetype/truetype"
+)
+
+// RenderSVG renders the plot as an SVG document of the provided size and
+// writes it to w. Text refers to the theme's fonts by family name.
+func (p *Plot) RenderSVG(w io.Writer, theme string, width, height int) error {
+	th, err := lookupTheme(theme)
+	if err != nil {
+		return err
+	}
+	sc := newSVGCanvas(w, width, height)
+	if err := p.draw(sc, th, float64(width), float64(height)); err != nil {
+		return err
+	}
+	return sc.finish()
+}
+
+// svgCanvas is a canvas that writes out an SVG document.
+type svgCanvas struct {
+	w     *bufio.Writer
+	state svgState
+	stack []svgState
+	path  strings.Builder
+
+	// lone is the circle making up the entire current path, if any, so it
+	// may be written out as a circle element.
+	lone *[3]float64
+
+	faceCache
+}
+
+// svgState is the graphics state saved and restored by push and pop.
+type svgState struct {
+	color     color.Color
+	lineWidth float64
+	lineCap   lineCap
+	lineJoin  lineJoin
+	font      *truetype.Font
+	fontSize  float64
+
+	// groups is the number of <g> elements opened since the last push.
+	groups int
+}
+
+func newSVGCanvas(w io.Writer, width, height int) *svgCanvas {
+	sc := &svgCanvas{
+		w: bufio.NewWriter(w),
+		state: svgState{
+			color:     color.Black,
+			lineWidth: 1,
+		},
+		faceCache: make(faceCache),
+	}
+	fmt.Fprintf(sc.w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", width, height, width, height)
+	return sc
+}
+
+// finish closes any open elements and flushes the document.
+func (sc *svgCanvas) finish() error {
+	for len(sc.stack) > 0 {
+		sc.pop()
+	}
+	sc.closeGroups()
+	io.WriteString(sc.w, "</svg>\n")
+	return sc.w.Flush()
+}
+
+func (sc *svgCanvas) setColor(c color.Color) {
+	sc.state.color = c
+}
+
+func (sc *svgCanvas) setLineWidth(w float64) {
+	sc.state.lineWidth = w
+}
+
+func (sc *svgCanvas) setLineCap(lc lineCap) {
+	sc.state.lineCap = lc
+}
+
+func (sc *svgCanvas) setLineJoin(lj lineJoin) {
+	sc.state.lineJoin = lj
+}
+
+func (sc *svgCanvas) setFont(f *truetype.Font, size float64) {
+	sc.state.font = f
+	sc.state.fontSize = size
+}
+
+func (sc *svgCanvas) moveTo(x, y float64) {
+	sc.lone = nil
+	fmt.Fprintf(&sc.path, "M%s %s", svgNum(x), svgNum(y))
+}
+
+func (sc *svgCanvas) lineTo(x, y float64) {
+	sc.lone = nil
+	fmt.Fprintf(&sc.path, "L%s %s", svgNum(x), svgNum(y))
+}
+
+func (sc *svgCanvas) closePath() {
+	sc.lone = nil
+	sc.path.WriteString("Z")
+}
+
+func (sc *svgCanvas) circle(x, y, r float64) {
+	if sc.path.Len() == 0 {
+		sc.lone = &[3]float64{x, y, r}
+	} else {
+		sc.lone = nil
+	}
+	fmt.Fprintf(&sc.path, "M%s %sA%s %s 0 1 0 %s %sA%s %s 0 1 0 %s %sZ",
+		svgNum(x+r), svgNum(y),
+		svgNum(r), svgNum(r), svgNum(x-r), svgNum(y),
+		svgNum(r), svgNum(r), svgNum(x+r), svgNum(y))
+}
+
+func (sc *svgCanvas) rect(x, y, w, h float64) {
+	sc.lone = nil
+	fmt.Fprintf(&sc.path, "M%s %sh%sv%sh%sZ", svgNum(x), svgNum(y), svgNum(w), svgNum(h), svgNum(-w))
+}
+
+func (sc *svgCanvas) stroke() {
+	if sc.path.Len() == 0 {
+		return
+	}
+	fmt.Fprintf(sc.w, `<path d="%s" fill="none" stroke="%s"%s stroke-width="%s"`,
+		sc.path.String(), svgColor(sc.state.color), svgOpacity("stroke-opacity", sc.state.color), svgNum(sc.state.lineWidth))
+	switch sc.state.lineCap {
+	case lineCapRound:
+		io.WriteString(sc.w, ` stroke-linecap="round"`)
+	case lineCapSquare:
+		io.WriteString(sc.w, ` stroke-linecap="square"`)
+	}
+	switch sc.state.lineJoin {
+	case lineJoinRound:
+		io.WriteString(sc.w, ` stroke-linejoin="round"`)
+	case lineJoinBevel:
+		io.WriteString(sc.w, ` stroke-linejoin="bevel"`)
+	}
+	io.WriteString(sc.w, "/>\n")
+	sc.resetPath()
+}
+
+func (sc *svgCanvas) fill() {
+	if sc.path.Len() == 0 {
+		return
+	}
+	if c := sc.lone; c != nil {
+		fmt.Fprintf(sc.w, `<circle cx="%s" cy="%s" r="%s" fill="%s"%s/>`+"\n",
+			svgNum(c[0]), svgNum(c[1]), svgNum(c[2]), svgColor(sc.state.color), svgOpacity("fill-opacity", sc.state.color))
+	} else {
+		fmt.Fprintf(sc.w, `<path d="%s" fill="%s"%s/>`+"\n",
+			sc.path.String(), svgColor(sc.state.color), svgOpacity("fill-opacity", sc.state.color))
+	}
+	sc.resetPath()
+}
+
+func (sc *svgCanvas) resetPath() {
+	sc.path.Reset()
+	sc.lone = nil
+}
+
+func (sc *svgCanvas) drawString(s string, x, y float64) {
+	family := "sans-serif"
+	if sc.state.font != nil {
+		family = "'" + sc.state.font.Name(truetype.NameIDFontFamily) + "', " + family
+	}
+	fmt.Fprintf(sc.w, `<text x="%s" y="%s" font-family="%s" font-size="%s" fill="%s"%s xml:space="preserve">`,
+		svgNum(x), svgNum(y), svgEscape(family), svgNum(sc.state.fontSize), svgColor(sc.state.color), svgOpacity("fill-opacity", sc.state.color))
+	io.WriteString(sc.w, svgEscape(s))
+	io.WriteString(sc.w, "</text>\n")
+}
+
+func (sc *svgCanvas) beginGroup(class, series string) {
+	fmt.Fprintf(sc.w, `<g class="%s"`, svgEscape(class))
+	if series != "" {
+		fmt.Fprintf(sc.w, ` data-series="%s"`, svgEscape(series))
+	}
+	io.WriteString(sc.w, ">\n")
+}
+
+func (sc *svgCanvas) endGroup() {
+	io.WriteString(sc.w, "</g>\n")
+}
+
+func (sc *svgCanvas) push() {
+	sc.stack = append(sc.stack, sc.state)
+	sc.state.groups = 0
+}
+
+func (sc *svgCanvas) pop() {
+	sc.closeGroups()
+	sc.state = sc.stack[len(sc.stack)-1]
+	sc.stack = sc.stack[:len(sc.stack)-1]
+}
+
+// closeGroups closes all the groups opened since the last push.
+func (sc *svgCanvas) closeGroups() {
+	for range sc.state.groups {
+		io.WriteString(sc.w, "</g>\n")
+	}
+	sc.state.groups = 0
+}
+
+func (sc *svgCanvas) translate(x, y float64) {
+	fmt.Fprintf(sc.w, `<g transform="translate(%s %s)">`+"\n", svgNum(x), svgNum(y))
+	sc.state.groups++
+}
+
+func (sc *svgCanvas) rotate(angle float64) {
+	fmt.Fprintf(sc.w, `<g transform="rotate(%s)">`+"\n", svgNum(angle*180/math.Pi))
+	sc.state.groups++
+}
+
+// svgNum formats a coordinate compactly.
+func svgNum(x float64) string {
+	return strconv.FormatFloat(math.Round(x*100)/100, 'f', -1, 64)
+}
+
+// svgColor formats the opaque part of c.
+func svgColor(c color.Color) string {
+	r, g, b, a := c.RGBA()
+	if a == 0 {
+		return "none"
+	}
+	// Un-premultiply.
+	r, g, b = r*0xffff/a, g*0xffff/a, b*0xffff/a
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
+
+// svgOpacity formats the opacity of c as attr, or nothing if c is opaque.
+func svgOpacity(attr string, c color.Color) string {
+	_, _, _, a := c.RGBA()
+	if a == 0xffff || a == 0 {
+		return ""
+	}
+	return fmt.Sprintf(` %s="%s"`, attr, strconv.FormatFloat(float64(a)/0xffff, 'f', 3, 64))
+}
+
+var svgEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	`"`, "&quot;",
+)
+
+func svgEscape(s string) string {
+	return svgEscaper.Replace(s)
+}
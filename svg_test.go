@@ -0,0 +1,127 @@
+package ggg_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"slices"
+	"testing"
+
+	. "github.com/mknyszek/ggg"
+	_ "github.com/mknyszek/ggg/themes/dark"
+)
+
+// xmlNode is an element of a parsed XML document.
+type xmlNode struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Children []xmlNode  `xml:",any"`
+	Text     string     `xml:",chardata"`
+}
+
+func (n *xmlNode) attr(name string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// find returns the elements within n, including n itself, that are named
+// name and have the class, if not empty.
+func (n *xmlNode) find(name, class string) []*xmlNode {
+	var found []*xmlNode
+	if n.XMLName.Local == name && (class == "" || n.attr("class") == class) {
+		found = append(found, n)
+	}
+	for i := range n.Children {
+		found = append(found, n.Children[i].find(name, class)...)
+	}
+	return found
+}
+
+func TestRenderSVG(t *testing.T) {
+	colX := NewColumn[float64]("x")
+	colY := NewColumn[float64]("y")
+	colS := NewColumn[string]("name")
+	d := Empty()
+	d.AddColumn(colX)
+	d.AddColumn(colY)
+	d.AddColumn(colS)
+	for row := range d.Grow(6) {
+		colX.Set(d, row, float64(row))
+		colY.Set(d, row, float64(row*row))
+		colS.Set(d, row, []string{"cod", `"eel" & <ray>`}[row%2])
+	}
+
+	type test struct {
+		name string
+		plot *Plot
+
+		// mark is the element each series is drawn with.
+		mark string
+	}
+	for _, ts := range []test{
+		{
+			name: "Lines",
+			plot: LinePlot(d, colX, colY, colS),
+			mark: "path",
+		},
+		{
+			name: "Points",
+			plot: ScatterPlot(d, colX, colY, colS),
+			mark: "circle",
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			p := ts.plot.Presentation(Title("Fish <&> chips"), XAxis("boxes"), Legend(LegendTopRight))
+			var buf bytes.Buffer
+			if err := p.RenderSVG(&buf, "dark", 300, 200); err != nil {
+				t.Fatal(err)
+			}
+			var root xmlNode
+			if err := xml.Unmarshal(buf.Bytes(), &root); err != nil {
+				t.Fatalf("output isn't XML: %v\n%s", err, buf.String())
+			}
+			if root.XMLName.Local != "svg" || root.attr("width") != "300" || root.attr("height") != "200" {
+				t.Errorf("got root <%s width=%q height=%q>, want a 300 by 200 <svg>",
+					root.XMLName.Local, root.attr("width"), root.attr("height"))
+			}
+			for _, class := range []string{"chart", "x-axis", "data", "legend"} {
+				if len(root.find("g", class)) == 0 {
+					t.Errorf("no %q group", class)
+				}
+			}
+
+			// Each series is drawn in its own group, and has a legend entry
+			// for the same series.
+			want := []string{"cod", `"eel" & <ray>`}
+			var series, entries []string
+			for _, g := range root.find("g", "series") {
+				series = append(series, g.attr("data-series"))
+				if len(g.find(ts.mark, "")) == 0 {
+					t.Errorf("series %q has no <%s>", g.attr("data-series"), ts.mark)
+				}
+			}
+			for _, g := range root.find("g", "legend-entry") {
+				entries = append(entries, g.attr("data-series"))
+			}
+			if !slices.Equal(series, want) {
+				t.Errorf("got series %q, want %q", series, want)
+			}
+			if !slices.Equal(entries, want) {
+				t.Errorf("got legend entries %q, want %q", entries, want)
+			}
+
+			var texts []string
+			for _, n := range root.find("text", "") {
+				texts = append(texts, n.Text)
+			}
+			for _, s := range []string{"Fish <&> chips", "boxes", "0", "25", "cod", `"eel" & <ray>`} {
+				if !slices.Contains(texts, s) {
+					t.Errorf("no text %q among %q", s, texts)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package termimg
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"io"
+)
+
+// kittyChunk is the maximum size of the payload of each escape sequence.
+const kittyChunk = 4096
+
+// EncodeKitty writes img to w as a sequence of Kitty graphics protocol
+// escape sequences, transmitting it as a PNG and displaying it at the
+// cursor.
+func EncodeKitty(w io.Writer, img image.Image) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+	data := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	// Only the first chunk carries the control data. q=2 suppresses the
+	// terminal's responses.
+	bw := bufio.NewWriter(w)
+	ctrl := "a=T,f=100,q=2,"
+	for {
+		n := min(len(data), kittyChunk)
+		more := "0"
+		if n < len(data) {
+			more = "1"
+		}
+		bw.WriteString("\x1b_G" + ctrl + "m=" + more + ";")
+		bw.WriteString(data[:n])
+		bw.WriteString("\x1b\\")
+		data = data[n:]
+		ctrl = ""
+		if len(data) == 0 {
+			break
+		}
+	}
+	return bw.Flush()
+}
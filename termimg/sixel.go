@@ -0,0 +1,97 @@
+package termimg
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"io"
+	"slices"
+
+	"github.com/mknyszek/ggg/internal/quant"
+)
+
+// sixelColors is the number of color registers used. Most terminals
+// support at least 256.
+const sixelColors = 256
+
+// EncodeSixel writes img to w as a Sixel escape sequence, reducing it to at
+// most 256 colors.
+func EncodeSixel(w io.Writer, img image.Image) error {
+	pal := quant.Palette(img, sixelColors)
+	pm := quant.Paletted(img, pal)
+	b := pm.Bounds()
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "\x1bPq\"1;1;%d;%d", b.Dx(), b.Dy())
+	for i, c := range pal {
+		r, g, b, _ := c.RGBA()
+		fmt.Fprintf(bw, "#%d;2;%d;%d;%d", i, percent(r), percent(g), percent(b))
+	}
+
+	// Each band is six pixels tall, and is drawn one color at a time, with
+	// a character for each column encoding which of its pixels have that
+	// color.
+	var rows [sixelColors][]byte
+	for y0 := b.Min.Y; y0 < b.Max.Y; y0 += 6 {
+		if y0 != b.Min.Y {
+			bw.WriteByte('-')
+		}
+		for i := range rows {
+			rows[i] = rows[i][:0]
+		}
+		for dy := range min(6, b.Max.Y-y0) {
+			off := pm.PixOffset(b.Min.X, y0+dy)
+			for x, i := range pm.Pix[off : off+b.Dx()] {
+				if len(rows[i]) == 0 {
+					rows[i] = slices.Grow(rows[i], b.Dx())[:b.Dx()]
+					for x := range rows[i] {
+						rows[i][x] = '?'
+					}
+				}
+				rows[i][x] += 1 << dy
+			}
+		}
+		first := true
+		for i, row := range rows {
+			if len(row) == 0 {
+				continue
+			}
+			if !first {
+				bw.WriteByte('$')
+			}
+			first = false
+			fmt.Fprintf(bw, "#%d", i)
+			writeSixelRun(bw, row)
+		}
+	}
+	bw.WriteString("\x1b\\")
+	return bw.Flush()
+}
+
+// writeSixelRun writes row, run-length encoded, omitting trailing empty
+// columns.
+func writeSixelRun(w *bufio.Writer, row []byte) {
+	end := len(row)
+	for end > 0 && row[end-1] == '?' {
+		end--
+	}
+	for x := 0; x < end; {
+		n := 1
+		for x+n < end && row[x+n] == row[x] {
+			n++
+		}
+		if n > 3 {
+			fmt.Fprintf(w, "!%d%c", n, row[x])
+		} else {
+			for range n {
+				w.WriteByte(row[x])
+			}
+		}
+		x += n
+	}
+}
+
+// percent scales a 16-bit color channel to 0-100.
+func percent(v uint32) uint32 {
+	return (v*100 + 0xffff/2) / 0xffff
+}
@@ -0,0 +1,72 @@
+// Package termimg displays images inline in terminals that support the
+// Sixel or Kitty graphics protocols.
+package termimg
+
+import (
+	"errors"
+	"image"
+	"io"
+	"os"
+	"strings"
+)
+
+// Protocol is a terminal graphics protocol.
+type Protocol int
+
+const (
+	// None means the terminal can't display images.
+	None Protocol = iota
+
+	// Sixel is the DEC Sixel protocol, supported by foot, WezTerm, and
+	// xterm among others.
+	Sixel
+
+	// Kitty is the Kitty graphics protocol, supported by kitty, WezTerm,
+	// and Ghostty.
+	Kitty
+)
+
+func (p Protocol) String() string {
+	switch p {
+	case Sixel:
+		return "sixel"
+	case Kitty:
+		return "kitty"
+	}
+	return "none"
+}
+
+// Detect guesses which protocol the terminal supports from the environment,
+// preferring Kitty where both are available.
+func Detect() Protocol {
+	return detect(os.Getenv)
+}
+
+func detect(getenv func(string) string) Protocol {
+	term := getenv("TERM")
+	switch {
+	case getenv("KITTY_WINDOW_ID") != "",
+		strings.HasPrefix(term, "xterm-kitty"),
+		strings.HasPrefix(term, "xterm-ghostty"),
+		getenv("TERM_PROGRAM") == "WezTerm",
+		getenv("TERM_PROGRAM") == "ghostty":
+		return Kitty
+	case term == "foot",
+		strings.HasPrefix(term, "foot-"),
+		strings.HasPrefix(term, "mlterm"),
+		strings.Contains(term, "sixel"):
+		return Sixel
+	}
+	return None
+}
+
+// Encode writes img to w as an escape sequence that displays it using p.
+func Encode(w io.Writer, img image.Image, p Protocol) error {
+	switch p {
+	case Sixel:
+		return EncodeSixel(w, img)
+	case Kitty:
+		return EncodeKitty(w, img)
+	}
+	return errors.New("termimg: no graphics protocol")
+}
@@ -0,0 +1,104 @@
+package termimg
+
+import (
+	"bytes"
+	"flag"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// testImage returns a small image with a diagonal line and a square.
+func testImage(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			c := color.RGBA{0x22, 0x22, 0x22, 0xff}
+			switch {
+			case x == y:
+				c = color.RGBA{0xff, 0x00, 0x00, 0xff}
+			case x >= w/2 && y >= h/2:
+				c = color.RGBA{0x00, 0x80, 0xff, 0xff}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestEncode(t *testing.T) {
+	type test struct {
+		name  string
+		proto Protocol
+		img   image.Image
+	}
+	for _, ts := range []test{
+		{"Sixel", Sixel, testImage(10, 8)},
+		{"Kitty", Kitty, testImage(10, 8)},
+		// Large enough to need several Kitty chunks.
+		{"KittyChunked", Kitty, noise(64, 64)},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Encode(&buf, ts.img, ts.proto); err != nil {
+				t.Fatal(err)
+			}
+			golden := filepath.Join("testdata", ts.name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("output differs from %s:\ngot  %q\nwant %q", golden, buf.Bytes(), want)
+			}
+		})
+	}
+}
+
+// noise returns an image of pseudo-random colors, which compresses poorly.
+func noise(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	v := uint32(1)
+	for i := range img.Pix {
+		v = v*1664525 + 1013904223
+		img.Pix[i] = byte(v >> 24)
+	}
+	return img
+}
+
+func TestEncodeNone(t *testing.T) {
+	if err := Encode(new(bytes.Buffer), testImage(1, 1), None); err == nil {
+		t.Error("expected error encoding with no protocol")
+	}
+}
+
+func TestDetect(t *testing.T) {
+	type test struct {
+		env  map[string]string
+		want Protocol
+	}
+	for _, ts := range []test{
+		{map[string]string{}, None},
+		{map[string]string{"TERM": "xterm-256color"}, None},
+		{map[string]string{"TERM": "xterm-kitty"}, Kitty},
+		{map[string]string{"TERM": "screen", "KITTY_WINDOW_ID": "1"}, Kitty},
+		{map[string]string{"TERM": "xterm-256color", "TERM_PROGRAM": "WezTerm"}, Kitty},
+		{map[string]string{"TERM": "foot"}, Sixel},
+		{map[string]string{"TERM": "foot-extra"}, Sixel},
+	} {
+		got := detect(func(k string) string { return ts.env[k] })
+		if got != ts.want {
+			t.Errorf("detect(%v) = %v, want %v", ts.env, got, ts.want)
+		}
+	}
+}
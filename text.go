@@ -0,0 +1,653 @@
+package ggg
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fogleman/gg"
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// TextOption configures how a plot is rendered as text.
+type TextOption func(*textOptions)
+
+type textOptions struct {
+	blocks bool
+	colors TextColorMode
+}
+
+// TextColorMode is the set of colors available to text output.
+type TextColorMode int
+
+const (
+	// TextColor256 uses the 256-color ANSI palette, which almost all
+	// terminals support.
+	TextColor256 TextColorMode = iota
+
+	// TextTrueColor uses 24-bit ANSI colors.
+	TextTrueColor
+
+	// TextNoColor emits no escape sequences at all.
+	TextNoColor
+)
+
+// TextColors selects the colors used in text output. The default is
+// TextColor256.
+func TextColors(mode TextColorMode) TextOption {
+	return func(o *textOptions) {
+		o.colors = mode
+	}
+}
+
+// TextBlocks draws data with quadrant block characters, which have 2x2
+// dots per character, instead of braille, which has 2x4. Block characters
+// are available in more fonts.
+func TextBlocks() TextOption {
+	return func(o *textOptions) {
+		o.blocks = true
+	}
+}
+
+// RenderText renders the plot as cols by rows characters of text and
+// writes it to w, for display in a terminal. Axes and labels are drawn with
+// box-drawing characters and data with braille dots, colored with ANSI
+// escape sequences following the theme. Gridlines are omitted. The title
+// and subtitle are written on the top rows, and the caption on the bottom
+// row. Text is all the same size and unrotated, so TextScale, TitleScale,
+// TickLabelScale and RotateTickLabels have no effect. The output depends
+// only on the plot and the options. Only plots with Cartesian coordinates
+// can be rendered as text.
+func (p *Plot) RenderText(w io.Writer, cols, rows int, theme string, opts ...TextOption) error {
+	th, err := lookupTheme(theme)
+	if err != nil {
+		return err
+	}
+	if p.opts.coord != coordCartesian {
+		return fmt.Errorf("only plots with Cartesian coordinates can be rendered as text")
+	}
+	if cols <= 0 || rows <= 0 {
+		return fmt.Errorf("%d columns and %d rows are too few to render plot as text", cols, rows)
+	}
+	var o textOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	tc := newTextCanvas(cols, rows, o.blocks)
+	tc.fillCells(0, 0, cols, rows, th.BorderBackgroundColor)
+
+	// Lay out rows from the top and bottom.
+	top, bottom := 0, rows
+	if p.opts.title != "" {
+		tc.putText(0, top, p.opts.title, th.ForegroundColor)
+		top++
+	}
+	if p.opts.subtitle != "" {
+		tc.putText(0, top, p.opts.subtitle, th.ForegroundColor)
+		top++
+	}
+	if p.opts.caption != "" {
+		bottom--
+		tc.putText(max(cols-utf8.RuneCountInString(p.opts.caption), 0), bottom, p.opts.caption, th.ForegroundColor)
+	}
+	if p.opts.y.title != "" || p.usesY2() && p.opts.y2.title != "" {
+		tc.putText(0, top, p.opts.y.title, th.ForegroundColor)
+		if p.usesY2() {
+			tc.putText(cols-utf8.RuneCountInString(p.opts.y2.title), top, p.opts.y2.title, th.ForegroundColor)
+		}
+		top++
+	}
+	var legend []legendEntry
+	if p.opts.legend.shown {
+		legend = p.legendEntries(th)
+	}
+	legendRow := -1
+	if len(legend) != 0 {
+		bottom--
+		legendRow = bottom
+	}
+	xTitleRow := -1
+	if p.opts.x.title != "" {
+		bottom--
+		xTitleRow = bottom
+	}
+	xLabelRow := bottom - 1
+	axisRow := bottom - 2
+	if len(p.layers) == 0 {
+		return tc.writeTo(w, o.colors)
+	}
+	if axisRow-top < 2 {
+		return fmt.Errorf("%d rows are too few to render plot as text", rows)
+	}
+
+	// Lay out columns, leaving room for the Y tick labels.
+	p = p.prepared()
+	p.computeRanges()
+	if err := p.checkAxes(); err != nil {
+		return err
+	}
+	yTicks := p.opts.y.ticks()
+	yLabels := make([]string, len(yTicks))
+	var axisCol int
+	for i, y := range yTicks {
+		yLabels[i] = p.opts.y.tickLabel(y)
+		axisCol = max(axisCol, utf8.RuneCountInString(yLabels[i]))
+	}
+	// The chart area ends at the secondary Y axis, if any.
+	var y2Ticks []float64
+	var y2Labels []string
+	right := cols
+	if p.usesY2() {
+		y2Ticks = p.opts.y2.ticks()
+		var width int
+		for _, y := range y2Ticks {
+			label := p.opts.y2.tickLabel(y)
+			y2Labels = append(y2Labels, label)
+			width = max(width, utf8.RuneCountInString(label))
+		}
+		right = cols - width - 1
+	}
+	if right-axisCol-1 < 2 {
+		return fmt.Errorf("%d columns are too few to render plot as text", cols)
+	}
+	tc.fillCells(axisCol+1, top, right, axisRow, th.ChartBackgroundColor)
+
+	// Scale into the centers of the edge dots of the chart area.
+	x0, x1 := float64((axisCol+1)*tc.dotW), float64(right*tc.dotW)
+	y0, y1 := float64(top*tc.dotH), float64(axisRow*tc.dotH)
+	xScale, yScale, y2Scale, err := p.scales(x0+0.5, x1-0.5, y0+0.5, y1-0.5)
+	if err != nil {
+		return err
+	}
+
+	// Axes, ticks, and their labels.
+	for row := top; row < axisRow; row++ {
+		tc.putText(axisCol, row, "│", th.ForegroundColor)
+	}
+	tc.putText(axisCol, axisRow, "└"+strings.Repeat("─", right-axisCol-1), th.ForegroundColor)
+	for i, y := range yTicks {
+		row := int(yScale(y)) / tc.dotH
+		if row < top || row >= axisRow {
+			continue
+		}
+		tc.putText(axisCol, row, "┤", th.ForegroundColor)
+		tc.putText(axisCol-utf8.RuneCountInString(yLabels[i]), row, yLabels[i], th.ForegroundColor)
+	}
+	if p.usesY2() {
+		for row := top; row < axisRow; row++ {
+			tc.putText(right, row, "│", th.ForegroundColor)
+		}
+		tc.putText(right, axisRow, "┘", th.ForegroundColor)
+		for i, y := range y2Ticks {
+			row := int(y2Scale(y)) / tc.dotH
+			if row < top || row >= axisRow {
+				continue
+			}
+			tc.putText(right, row, "├"+y2Labels[i], th.ForegroundColor)
+		}
+	}
+	labelEnd := 0
+	for _, x := range p.opts.x.ticks() {
+		col := int(xScale(x)) / tc.dotW
+		if col <= axisCol || col >= right {
+			continue
+		}
+		tc.putText(col, axisRow, "┬", th.ForegroundColor)
+		label := p.opts.x.tickLabel(x)
+		n := utf8.RuneCountInString(label)
+		start := min(max(col-n/2, 0), cols-n)
+		if start < labelEnd {
+			// Don't let labels run into each other.
+			continue
+		}
+		tc.putText(start, xLabelRow, label, th.ForegroundColor)
+		labelEnd = start + n + 1
+	}
+
+	// Titles and legend.
+	if xTitleRow >= 0 {
+		n := utf8.RuneCountInString(p.opts.x.title)
+		tc.putText(max(axisCol+1+(right-axisCol-1-n)/2, 0), xTitleRow, p.opts.x.title, th.ForegroundColor)
+	}
+	col := axisCol + 1
+	for _, e := range legend {
+		if b := e.bar; b != nil {
+			tc.putText(col, legendRow, e.label+" "+b.label(b.lo), th.ForegroundColor)
+			col += utf8.RuneCountInString(e.label+" "+b.label(b.lo)) + 1
+			const steps = 8
+			for i := range steps {
+				tc.putText(col, legendRow, "█", b.scale((float64(i)+0.5)/steps))
+				col++
+			}
+			tc.putText(col+1, legendRow, b.label(b.hi), th.ForegroundColor)
+			col += 4 + utf8.RuneCountInString(b.label(b.hi))
+			continue
+		}
+		marker := "━"
+		switch {
+		case e.fill:
+			marker = "■"
+		case !e.line:
+			marker = "●"
+		}
+		tc.putText(col, legendRow, marker, e.color)
+		tc.putText(col+2, legendRow, e.label, th.ForegroundColor)
+		col += 4 + utf8.RuneCountInString(e.label)
+	}
+
+	// Draw layers. Sizes are scaled down so that lines and points are a
+	// single dot wide.
+	for _, l := range p.layers {
+		tc.push()
+		ys := yScale
+		if l.axis == Y2 {
+			ys = y2Scale
+		}
+		err := renderLayer(tc, th, l.AnyLayer, xScale, ys, 0.5, chartArea{x0, y0, x1, y1})
+		tc.pop()
+		if err != nil {
+			return err
+		}
+	}
+	return tc.writeTo(w, o.colors)
+}
+
+// textCanvas is a canvas that draws into a grid of character cells. Shapes
+// are drawn as dots, several to a cell, and each cell takes the color of
+// the last dot drawn into it. Coordinates are in dots.
+type textCanvas struct {
+	cols, rows int
+	dotW, dotH int
+	cells      []textCell
+	glyph      func(mask uint8) rune
+	bit        func(x, y int) uint8
+
+	state textState
+	stack []textState
+	path  [][]gg.Point
+}
+
+type textCell struct {
+	// mask has a bit set for each dot drawn in the cell.
+	mask uint8
+
+	// r is text drawn in the cell, which takes priority over dots.
+	r rune
+
+	fg, bg color.Color
+}
+
+type textState struct {
+	color color.Color
+	m     gg.Matrix
+	clip  image.Rectangle
+}
+
+func newTextCanvas(cols, rows int, blocks bool) *textCanvas {
+	tc := &textCanvas{
+		cols:  cols,
+		rows:  rows,
+		cells: make([]textCell, cols*rows),
+	}
+	if blocks {
+		tc.dotW, tc.dotH = 2, 2
+		tc.glyph = blockGlyph
+		tc.bit = func(x, y int) uint8 { return 1 << (2*y + x) }
+	} else {
+		tc.dotW, tc.dotH = 2, 4
+		tc.glyph = brailleGlyph
+		tc.bit = brailleBit
+	}
+	tc.state = textState{
+		color: color.Black,
+		m:     gg.Identity(),
+		clip:  image.Rect(0, 0, cols*tc.dotW, rows*tc.dotH),
+	}
+	return tc
+}
+
+// brailleBit returns the bit for the dot at (x, y) within a braille cell.
+// The dots are numbered down the left column, then down the right, with the
+// bottom row numbered last.
+func brailleBit(x, y int) uint8 {
+	if y == 3 {
+		return 1 << (6 + x)
+	}
+	return 1 << (3*x + y)
+}
+
+func brailleGlyph(mask uint8) rune {
+	return 0x2800 + rune(mask)
+}
+
+// blockGlyphs are indexed by a mask of upper-left, upper-right, lower-left,
+// and lower-right quadrants, from the lowest bit.
+var blockGlyphs = []rune(" ▘▝▀▖▌▞▛▗▚▐▜▄▙▟█")
+
+func blockGlyph(mask uint8) rune {
+	return blockGlyphs[mask]
+}
+
+// fillCells sets the background color of the cells in [col0, col1) and
+// [row0, row1).
+func (tc *textCanvas) fillCells(col0, row0, col1, row1 int, bg color.Color) {
+	for row := row0; row < row1; row++ {
+		for col := col0; col < col1; col++ {
+			tc.cells[row*tc.cols+col].bg = bg
+		}
+	}
+}
+
+// putText writes s into the cells starting at (col, row), one rune per
+// cell, dropping anything outside the grid.
+func (tc *textCanvas) putText(col, row int, s string, fg color.Color) {
+	if row < 0 || row >= tc.rows {
+		return
+	}
+	for _, r := range s {
+		if col >= 0 && col < tc.cols {
+			cell := &tc.cells[row*tc.cols+col]
+			cell.r = r
+			cell.fg = fg
+		}
+		col++
+	}
+}
+
+// setDot draws the dot containing (x, y).
+func (tc *textCanvas) setDot(x, y float64) {
+	pt := image.Pt(int(math.Floor(x)), int(math.Floor(y)))
+	if !pt.In(tc.state.clip) {
+		return
+	}
+	cell := &tc.cells[pt.Y/tc.dotH*tc.cols+pt.X/tc.dotW]
+	cell.mask |= tc.bit(pt.X%tc.dotW, pt.Y%tc.dotH)
+	cell.fg = tc.state.color
+}
+
+func (tc *textCanvas) setColor(c color.Color) {
+	tc.state.color = c
+}
+
+// Dots have a fixed size, and text is set in the terminal's font, so line
+// styles and fonts are ignored.
+func (tc *textCanvas) setLineWidth(w float64)                 {}
+func (tc *textCanvas) setLineCap(lc lineCap)                  {}
+func (tc *textCanvas) setLineJoin(lj lineJoin)                {}
+func (tc *textCanvas) setFont(f *truetype.Font, size float64) {}
+
+func (tc *textCanvas) face(f *truetype.Font, size float64) font.Face {
+	return cellFace{tc.dotW, tc.dotH}
+}
+
+func (tc *textCanvas) moveTo(x, y float64) {
+	x, y = tc.state.m.TransformPoint(x, y)
+	tc.path = append(tc.path, []gg.Point{{X: x, Y: y}})
+}
+
+func (tc *textCanvas) lineTo(x, y float64) {
+	if len(tc.path) == 0 {
+		tc.moveTo(x, y)
+		return
+	}
+	x, y = tc.state.m.TransformPoint(x, y)
+	sub := &tc.path[len(tc.path)-1]
+	*sub = append(*sub, gg.Point{X: x, Y: y})
+}
+
+func (tc *textCanvas) closePath() {
+	if len(tc.path) == 0 {
+		return
+	}
+	sub := &tc.path[len(tc.path)-1]
+	*sub = append(*sub, (*sub)[0])
+}
+
+func (tc *textCanvas) circle(x, y, r float64) {
+	const n = 16
+	tc.moveTo(x+r, y)
+	for i := 1; i < n; i++ {
+		a := 2 * math.Pi * float64(i) / n
+		tc.lineTo(x+r*math.Cos(a), y+r*math.Sin(a))
+	}
+	tc.closePath()
+}
+
+func (tc *textCanvas) rect(x, y, w, h float64) {
+	tc.moveTo(x, y)
+	tc.lineTo(x+w, y)
+	tc.lineTo(x+w, y+h)
+	tc.lineTo(x, y+h)
+	tc.closePath()
+}
+
+func (tc *textCanvas) stroke() {
+	for _, sub := range tc.path {
+		if len(sub) == 1 {
+			tc.setDot(sub[0].X, sub[0].Y)
+		}
+		for i := 1; i < len(sub); i++ {
+			a, b := sub[i-1], sub[i]
+			n := math.Ceil(math.Max(math.Abs(b.X-a.X), math.Abs(b.Y-a.Y)))
+			for j := 0.0; j <= n; j++ {
+				t := 0.0
+				if n > 0 {
+					t = j / n
+				}
+				tc.setDot(a.X+(b.X-a.X)*t, a.Y+(b.Y-a.Y)*t)
+			}
+		}
+	}
+	tc.path = tc.path[:0]
+}
+
+// fill draws the dots whose centers are inside the path, using the
+// even-odd rule. Shapes too small to contain any dot centers are drawn as
+// the single dot containing their first point.
+func (tc *textCanvas) fill() {
+	bounds := tc.pathBounds().Intersect(tc.state.clip)
+	drawn := false
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if tc.inside(float64(x)+0.5, float64(y)+0.5) {
+				tc.setDot(float64(x), float64(y))
+				drawn = true
+			}
+		}
+	}
+	if !drawn && len(tc.path) != 0 {
+		tc.setDot(tc.pathCenter())
+	}
+	tc.path = tc.path[:0]
+}
+
+// pathBounds returns the dots covering the path.
+func (tc *textCanvas) pathBounds() image.Rectangle {
+	var r image.Rectangle
+	for i, sub := range tc.path {
+		for j, pt := range sub {
+			b := image.Rect(int(math.Floor(pt.X)), int(math.Floor(pt.Y)), int(math.Floor(pt.X))+1, int(math.Floor(pt.Y))+1)
+			if i == 0 && j == 0 {
+				r = b
+			} else {
+				r = r.Union(b)
+			}
+		}
+	}
+	return r
+}
+
+// pathCenter returns the average of the points in the first subpath.
+func (tc *textCanvas) pathCenter() (x, y float64) {
+	sub := tc.path[0]
+	for _, pt := range sub {
+		x += pt.X
+		y += pt.Y
+	}
+	return x / float64(len(sub)), y / float64(len(sub))
+}
+
+// inside reports whether (x, y) is inside the path.
+func (tc *textCanvas) inside(x, y float64) bool {
+	in := false
+	for _, sub := range tc.path {
+		for i := range sub {
+			a, b := sub[i], sub[(i+1)%len(sub)]
+			if (a.Y > y) != (b.Y > y) && x < a.X+(y-a.Y)*(b.X-a.X)/(b.Y-a.Y) {
+				in = !in
+			}
+		}
+	}
+	return in
+}
+
+// drawString writes s into the cells, starting at the cell containing the
+// dot just above (x, y).
+func (tc *textCanvas) drawString(s string, x, y float64) {
+	x, y = tc.state.m.TransformPoint(x, y)
+	col := int(math.Floor(x / float64(tc.dotW)))
+	row := int(math.Floor((y - 0.5) / float64(tc.dotH)))
+	tc.putText(col, row, s, tc.state.color)
+}
+
+func (tc *textCanvas) push() {
+	tc.stack = append(tc.stack, tc.state)
+}
+
+func (tc *textCanvas) pop() {
+	tc.state = tc.stack[len(tc.stack)-1]
+	tc.stack = tc.stack[:len(tc.stack)-1]
+}
+
+func (tc *textCanvas) translate(x, y float64) {
+	tc.state.m = gg.Translate(x, y).Multiply(tc.state.m)
+}
+
+func (tc *textCanvas) rotate(angle float64) {
+	tc.state.m = gg.Rotate(angle).Multiply(tc.state.m)
+}
+
+// writeTo writes out the cells as lines of text, with escape sequences for
+// colors according to mode.
+func (tc *textCanvas) writeTo(w io.Writer, mode TextColorMode) error {
+	bw := bufio.NewWriter(w)
+	for row := range tc.rows {
+		var last string
+		for _, cell := range tc.cells[row*tc.cols : (row+1)*tc.cols] {
+			r := cell.r
+			if r == 0 {
+				r = ' '
+				if cell.mask != 0 {
+					r = tc.glyph(cell.mask)
+				}
+			}
+			if mode != TextNoColor {
+				var sgr []string
+				if cell.fg != nil {
+					sgr = append(sgr, ansiColor(cell.fg, 38, mode))
+				}
+				if cell.bg != nil {
+					sgr = append(sgr, ansiColor(cell.bg, 48, mode))
+				}
+				if s := strings.Join(sgr, ";"); s != last {
+					fmt.Fprintf(bw, "\x1b[0;%sm", s)
+					last = s
+				}
+			}
+			bw.WriteRune(r)
+		}
+		if mode != TextNoColor {
+			bw.WriteString("\x1b[0m")
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// ansiColor returns SGR parameters selecting c as the foreground color if
+// base is 38, or the background color if base is 48.
+func ansiColor(c color.Color, base int, mode TextColorMode) string {
+	nc := color.NRGBAModel.Convert(c).(color.NRGBA)
+	if mode == TextTrueColor {
+		return fmt.Sprintf("%d;2;%d;%d;%d", base, nc.R, nc.G, nc.B)
+	}
+	return fmt.Sprintf("%d;5;%d", base, ansi256(nc))
+}
+
+// ansi256 returns the closest color to c in the 6x6x6 color cube or the
+// grayscale ramp of the 256-color palette.
+func ansi256(c color.NRGBA) int {
+	levels := [6]int{0, 95, 135, 175, 215, 255}
+	nearest := func(v uint8) int {
+		best := 0
+		for i, l := range levels {
+			if abs(int(v)-l) < abs(int(v)-levels[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+	dist := func(r, g, b int) int {
+		dr, dg, db := int(c.R)-r, int(c.G)-g, int(c.B)-b
+		return dr*dr + dg*dg + db*db
+	}
+	ri, gi, bi := nearest(c.R), nearest(c.G), nearest(c.B)
+	index := 16 + 36*ri + 6*gi + bi
+	d := dist(levels[ri], levels[gi], levels[bi])
+
+	avg := (int(c.R) + int(c.G) + int(c.B)) / 3
+	gray := min(max((avg-8+5)/10, 0), 23)
+	if v := 8 + 10*gray; dist(v, v, v) < d {
+		index = 232 + gray
+	}
+	return index
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// cellFace is a font face in which every character fills one cell of a
+// text canvas, measured in dots.
+type cellFace struct {
+	w, h int
+}
+
+func (cf cellFace) Close() error {
+	return nil
+}
+
+func (cf cellFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	return image.Rectangle{}, nil, image.Point{}, fixed.I(cf.w), false
+}
+
+func (cf cellFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	return fixed.R(0, -cf.h, cf.w, 0), fixed.I(cf.w), true
+}
+
+func (cf cellFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	return fixed.I(cf.w), true
+}
+
+func (cf cellFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	return 0
+}
+
+func (cf cellFace) Metrics() font.Metrics {
+	return font.Metrics{
+		Height:    fixed.I(cf.h),
+		Ascent:    fixed.I(cf.h),
+		CapHeight: fixed.I(cf.h),
+		XHeight:   fixed.I(cf.h / 2),
+	}
+}
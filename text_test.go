@@ -0,0 +1,182 @@
+package ggg_test
+
+import (
+	"bytes"
+	"flag"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/mknyszek/ggg"
+	_ "github.com/mknyszek/ggg/themes/dark"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestRenderText(t *testing.T) {
+	colX := NewColumn[int]("x")
+	colY := NewColumn[float64]("y")
+	colS := NewColumn[string]("name")
+	d := Empty()
+	d.AddColumn(colX)
+	d.AddColumn(colY)
+	d.AddColumn(colS)
+	for _, name := range []string{"mackerel", "herring"} {
+		i := 1
+		for row := range d.Grow(50) {
+			colS.Set(d, row, name)
+			colX.Set(d, row, i)
+			colY.Set(d, row, math.Sin(float64(i)/10+float64(len(name))))
+			i++
+		}
+	}
+	points := NewPlot().Layer(&Layer[int, float64]{
+		Data: d,
+		X:    colX,
+		Y:    colY,
+		Geom: Point(NiceColors(colS), Constant(2.0)),
+	}).Presentation(YAxis("tons of fish", Format(FormatFixed(1))), Legend(LegendTopRight))
+
+	type test struct {
+		name       string
+		plot       *Plot
+		cols, rows int
+		opts       []TextOption
+	}
+	for _, ts := range []test{
+		{
+			name: "Lines",
+			plot: LinePlot(d, colX, colY, colS).Presentation(
+				Title("My Chart"),
+				XAxis("boxes"),
+				YAxis("tons of fish"),
+				Legend(LegendTopRight),
+			),
+			cols: 80,
+			rows: 24,
+		},
+		{
+			name: "LinesBlocksTrueColor",
+			plot: LinePlot(d, colX, colY, colS).Presentation(NoLegend()),
+			cols: 60,
+			rows: 16,
+			opts: []TextOption{TextBlocks(), TextColors(TextTrueColor)},
+		},
+		{
+			name: "Captioned",
+			plot: LinePlot(d, colX, colY, colS).Presentation(
+				Title("My Chart"),
+				Subtitle("Fish per box"),
+				Caption("Source: the docks"),
+				XAxis("boxes"),
+				Legend(LegendTopRight),
+			),
+			cols: 60,
+			rows: 20,
+			opts: []TextOption{TextColors(TextNoColor)},
+		},
+		{
+			name: "PointsNoColor",
+			plot: points,
+			cols: 60,
+			rows: 20,
+			opts: []TextOption{TextColors(TextNoColor)},
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := ts.plot.RenderText(&buf, ts.cols, ts.rows, "dark", ts.opts...); err != nil {
+				t.Fatal(err)
+			}
+			golden := filepath.Join("testdata", "text", ts.name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("output differs from %s; got:\n%s", golden, buf.String())
+			}
+		})
+	}
+}
+
+func TestRenderTextErrors(t *testing.T) {
+	colX := NewColumn[float64]("x")
+	colY := NewColumn[float64]("y")
+	d := Empty()
+	d.AddColumn(colX)
+	d.AddColumn(colY)
+	for row := range d.Grow(10) {
+		colX.Set(d, row, float64(row))
+		colY.Set(d, row, float64(row*row))
+	}
+	points := func() *Plot {
+		return NewPlot().Layer(&Layer[float64, float64]{
+			Data: d,
+			X:    colX,
+			Y:    colY,
+			Geom: Point(PaletteColor(0), Constant(2.0)),
+		})
+	}
+
+	type test struct {
+		name       string
+		plot       *Plot
+		cols, rows int
+		want       string
+	}
+	for _, ts := range []test{
+		{
+			name: "Polar",
+			plot: points().Presentation(CoordPolar()),
+			cols: 60,
+			rows: 20,
+			want: "only plots with Cartesian coordinates can be rendered as text",
+		},
+		{
+			name: "LogScaleOverZero",
+			plot: points().Presentation(YAxis("", LogScale(10))),
+			cols: 60,
+			rows: 20,
+			want: "specified log scale, but domain of Y values is zero or negative",
+		},
+		{
+			name: "TooFewRows",
+			plot: points(),
+			cols: 60,
+			rows: 3,
+			want: "3 rows are too few to render plot as text",
+		},
+		{
+			name: "NegativeRows",
+			plot: points(),
+			cols: 60,
+			rows: -1,
+			want: "60 columns and -1 rows are too few to render plot as text",
+		},
+		{
+			// Even an empty plot needs room to be rendered.
+			name: "NegativeColumns",
+			plot: NewPlot(),
+			cols: -5,
+			rows: 20,
+			want: "-5 columns and 20 rows are too few to render plot as text",
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := ts.plot.RenderText(&buf, ts.cols, ts.rows, "dark")
+			if err == nil || !strings.Contains(err.Error(), ts.want) {
+				t.Errorf("got error %v, want one containing %q", err, ts.want)
+			}
+		})
+	}
+}
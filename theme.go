@@ -10,6 +10,7 @@ type Theme struct {
 	Name                  string
 	ForegroundColor       color.Color
 	GridlineColor         color.Color
+	MinorGridlineColor    color.Color // If nil, GridlineColor is used.
 	ChartBackgroundColor  color.Color
 	BorderBackgroundColor color.Color
 	SeriesPalette         func(uint64) color.Color
@@ -27,6 +27,7 @@ func init() {
 		ChartBackgroundColor:  &color.RGBA{0, 0, 0, 255},
 		ForegroundColor:       &color.RGBA{255, 255, 255, 255},
 		GridlineColor:         &color.RGBA{41, 41, 41, 255},
+		MinorGridlineColor:    &color.RGBA{20, 20, 20, 255},
 	})
 	if !ok {
 		panic(fmt.Sprintf("theme 'spaceage' already exists"))
@@ -27,6 +27,7 @@ func init() {
 		ChartBackgroundColor:  &color.RGBA{245, 236, 225, 255},
 		ForegroundColor:       &color.RGBA{41, 41, 41, 255},
 		GridlineColor:         &color.RGBA{41, 41, 41, 64},
+		MinorGridlineColor:    &color.RGBA{41, 41, 41, 24},
 	})
 	if !ok {
 		panic(fmt.Sprintf("theme 'spaceage' already exists"))
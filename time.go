@@ -0,0 +1,255 @@
+package ggg
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	day   = 24 * time.Hour
+	week  = 7 * day
+	month = 30 * day
+	year  = 365 * day
+)
+
+type timeUnit int
+
+const (
+	unitSecond timeUnit = iota
+	unitMinute
+	unitHour
+	unitDay
+	unitWeek
+	unitMonth
+	unitYear
+)
+
+// timeStep is a calendar-aware interval between time ticks.
+type timeStep struct {
+	unit timeUnit
+	n    int
+}
+
+// timeSteps are the intervals considered for time ticks, in increasing order.
+// Based on d3-time-scale's tick intervals.
+var timeSteps = []timeStep{
+	{unitSecond, 1},
+	{unitSecond, 5},
+	{unitSecond, 15},
+	{unitSecond, 30},
+	{unitMinute, 1},
+	{unitMinute, 5},
+	{unitMinute, 15},
+	{unitMinute, 30},
+	{unitHour, 1},
+	{unitHour, 3},
+	{unitHour, 6},
+	{unitHour, 12},
+	{unitDay, 1},
+	{unitDay, 2},
+	{unitWeek, 1},
+	{unitMonth, 1},
+	{unitMonth, 3},
+	{unitYear, 1},
+}
+
+// approx returns the approximate length of the step.
+func (s timeStep) approx() time.Duration {
+	var d time.Duration
+	switch s.unit {
+	case unitSecond:
+		d = time.Second
+	case unitMinute:
+		d = time.Minute
+	case unitHour:
+		d = time.Hour
+	case unitDay:
+		d = day
+	case unitWeek:
+		d = week
+	case unitMonth:
+		d = month
+	case unitYear:
+		d = year
+	}
+	return time.Duration(s.n) * d
+}
+
+// floor rounds t down to the nearest step boundary in t's location.
+func (s timeStep) floor(t time.Time) time.Time {
+	y, mo, d := t.Date()
+	h, mi, sec := t.Clock()
+	loc := t.Location()
+	switch s.unit {
+	case unitSecond:
+		return time.Date(y, mo, d, h, mi, sec-sec%s.n, 0, loc)
+	case unitMinute:
+		return time.Date(y, mo, d, h, mi-mi%s.n, 0, 0, loc)
+	case unitHour:
+		return time.Date(y, mo, d, h-h%s.n, 0, 0, 0, loc)
+	case unitDay:
+		return time.Date(y, mo, d-(d-1)%s.n, 0, 0, 0, 0, loc)
+	case unitWeek:
+		return time.Date(y, mo, d-int(t.Weekday()), 0, 0, 0, 0, loc)
+	case unitMonth:
+		return time.Date(y, mo-(mo-1)%time.Month(s.n), 1, 0, 0, 0, 0, loc)
+	case unitYear:
+		return time.Date(y-y%s.n, time.January, 1, 0, 0, 0, 0, loc)
+	}
+	panic("bad time unit")
+}
+
+// next returns the step boundary following t, which must be on a boundary.
+func (s timeStep) next(t time.Time) time.Time {
+	var a time.Time
+	switch s.unit {
+	case unitSecond:
+		a = t.Add(time.Duration(s.n) * time.Second)
+	case unitMinute:
+		a = t.Add(time.Duration(s.n) * time.Minute)
+	case unitHour:
+		a = t.Add(time.Duration(s.n) * time.Hour)
+	case unitDay:
+		a = t.AddDate(0, 0, s.n)
+	case unitWeek:
+		a = t.AddDate(0, 0, 7*s.n)
+	case unitMonth:
+		a = t.AddDate(0, s.n, 0)
+	case unitYear:
+		a = t.AddDate(s.n, 0, 0)
+	}
+	// Re-align to the step, since boundaries may be irregularly spaced
+	// (e.g. across month boundaries or daylight saving time transitions).
+	if f := s.floor(a); f.After(t) {
+		return f
+	}
+	return a
+}
+
+// timeTicks produces roughly count ticks between start and stop, which are
+// Unix times in nanoseconds. Ticks land on calendar boundaries in the local
+// time zone.
+func timeTicks(start, stop float64, count int) []float64 {
+	if count <= 0 {
+		count = 5
+	}
+	if stop < start {
+		start, stop = stop, start
+	}
+	target := time.Duration((stop - start) / float64(count))
+	if target < time.Second {
+		// Sub-second intervals are decimal, so linear ticks are just fine.
+		return linearTicks(start, stop, count)
+	}
+	lo, hi := floatToTime(start), floatToTime(stop)
+	if target > year {
+		var ticks []float64
+		for _, y := range linearTicks(float64(lo.Year()), float64(hi.Year()+1), count) {
+			if y != math.Trunc(y) {
+				continue
+			}
+			t := time.Date(int(y), time.January, 1, 0, 0, 0, 0, lo.Location())
+			if t.Before(lo) || t.After(hi) {
+				continue
+			}
+			ticks = append(ticks, float64(t.UnixNano()))
+		}
+		return ticks
+	}
+
+	// Pick the step closest to the target interval, by ratio.
+	step := timeSteps[0]
+	best := math.Inf(1)
+	for _, s := range timeSteps {
+		d := math.Abs(math.Log(float64(s.approx()) / float64(target)))
+		if d < best {
+			step, best = s, d
+		}
+	}
+	var ticks []float64
+	for t := step.floor(lo); !t.After(hi); t = step.next(t) {
+		if t.Before(lo) {
+			continue
+		}
+		ticks = append(ticks, float64(t.UnixNano()))
+	}
+	return ticks
+}
+
+// durationSteps are the intervals considered for duration ticks of at least
+// a minute, where decimal ticks would be unnatural.
+var durationSteps = []time.Duration{
+	time.Minute,
+	2 * time.Minute,
+	5 * time.Minute,
+	10 * time.Minute,
+	15 * time.Minute,
+	30 * time.Minute,
+	time.Hour,
+	2 * time.Hour,
+	3 * time.Hour,
+	6 * time.Hour,
+	12 * time.Hour,
+	24 * time.Hour,
+}
+
+// durationTicks produces roughly count ticks between start and stop, which
+// are durations in nanoseconds.
+func durationTicks(start, stop float64, count int) []float64 {
+	if count <= 0 {
+		count = 5
+	}
+	if stop < start {
+		start, stop = stop, start
+	}
+	target := (stop - start) / float64(count)
+	if target < float64(time.Minute)/2 {
+		return linearTicks(start, stop, count)
+	}
+	if target > float64(day) {
+		ticks := linearTicks(start/float64(time.Hour), stop/float64(time.Hour), count)
+		for i := range ticks {
+			ticks[i] *= float64(time.Hour)
+		}
+		return ticks
+	}
+	step := float64(durationSteps[0])
+	best := math.Inf(1)
+	for _, s := range durationSteps {
+		d := math.Abs(math.Log(float64(s) / target))
+		if d < best {
+			step, best = float64(s), d
+		}
+	}
+	var ticks []float64
+	for x := math.Ceil(start/step) * step; x <= stop; x += step {
+		ticks = append(ticks, x)
+	}
+	return ticks
+}
+
+// floatToTime converts a position along a time axis back into a time.
+func floatToTime(x float64) time.Time {
+	// Round to the microsecond, since float64 can't represent present-day
+	// Unix times with nanosecond precision anyway.
+	return time.Unix(0, int64(math.Round(x))).Round(time.Microsecond)
+}
+
+// formatTime formats a position along a time axis as a label, choosing the
+// coarsest format that doesn't lose information.
+func formatTime(x float64) string {
+	t := floatToTime(x)
+	switch {
+	case t.Nanosecond() != 0:
+		return t.Format("15:04:05.999999")
+	case t.Second() != 0:
+		return t.Format("15:04:05")
+	case t.Minute() != 0 || t.Hour() != 0:
+		return t.Format("15:04")
+	case t.Day() != 1:
+		return t.Format("Jan 2")
+	case t.Month() != time.January:
+		return t.Format("Jan")
+	}
+	return t.Format("2006")
+}
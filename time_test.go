@@ -0,0 +1,192 @@
+package ggg
+
+import (
+	"slices"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestTimeTicks(t *testing.T) {
+	// Ticks fall on boundaries in the local time zone.
+	local := time.Local
+	time.Local = time.UTC
+	t.Cleanup(func() { time.Local = local })
+
+	at := func(s string) float64 {
+		tm, err := time.Parse(time.DateTime, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return float64(tm.UnixNano())
+	}
+	type test struct {
+		name        string
+		start, stop string
+		count       int
+		want        []string
+	}
+	for _, ts := range []test{
+		{
+			name:  "Hours",
+			start: "2024-01-01 00:00:00",
+			stop:  "2024-01-02 00:00:00",
+			count: 5,
+			want: []string{
+				"2024-01-01 00:00:00",
+				"2024-01-01 06:00:00",
+				"2024-01-01 12:00:00",
+				"2024-01-01 18:00:00",
+				"2024-01-02 00:00:00",
+			},
+		},
+		{
+			name:  "Unaligned",
+			start: "2024-01-01 01:10:00",
+			stop:  "2024-01-01 01:50:00",
+			count: 4,
+			want: []string{
+				"2024-01-01 01:15:00",
+				"2024-01-01 01:30:00",
+				"2024-01-01 01:45:00",
+			},
+		},
+		{
+			name:  "Months",
+			start: "2024-01-01 00:00:00",
+			stop:  "2024-05-01 00:00:00",
+			count: 4,
+			want: []string{
+				"2024-01-01 00:00:00",
+				"2024-02-01 00:00:00",
+				"2024-03-01 00:00:00",
+				"2024-04-01 00:00:00",
+				"2024-05-01 00:00:00",
+			},
+		},
+		{
+			name:  "Years",
+			start: "2000-06-01 00:00:00",
+			stop:  "2030-06-01 00:00:00",
+			count: 3,
+			want: []string{
+				"2010-01-01 00:00:00",
+				"2020-01-01 00:00:00",
+				"2030-01-01 00:00:00",
+			},
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			var want []float64
+			for _, s := range ts.want {
+				want = append(want, at(s))
+			}
+			got := timeTicks(at(ts.start), at(ts.stop), ts.count)
+			if !slices.Equal(got, want) {
+				t.Errorf("got ticks %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDurationTicks(t *testing.T) {
+	type test struct {
+		name        string
+		start, stop time.Duration
+		want        []time.Duration
+	}
+	for _, ts := range []test{
+		{
+			name:  "SubMinute",
+			start: 0,
+			stop:  time.Second,
+			want:  []time.Duration{0, 200 * time.Millisecond, 400 * time.Millisecond, 600 * time.Millisecond, 800 * time.Millisecond, time.Second},
+		},
+		{
+			name:  "Minutes",
+			start: 0,
+			stop:  10 * time.Minute,
+			want:  []time.Duration{0, 2 * time.Minute, 4 * time.Minute, 6 * time.Minute, 8 * time.Minute, 10 * time.Minute},
+		},
+		{
+			name:  "Hours",
+			start: time.Hour,
+			stop:  3 * 24 * time.Hour,
+			want:  []time.Duration{12 * time.Hour, 24 * time.Hour, 36 * time.Hour, 48 * time.Hour, 60 * time.Hour, 72 * time.Hour},
+		},
+		{
+			name:  "Days",
+			start: 0,
+			stop:  10 * 24 * time.Hour,
+			want:  []time.Duration{0, 50 * time.Hour, 100 * time.Hour, 150 * time.Hour, 200 * time.Hour},
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			var want []float64
+			for _, d := range ts.want {
+				want = append(want, float64(d))
+			}
+			got := durationTicks(float64(ts.start), float64(ts.stop), 5)
+			if !slices.Equal(got, want) {
+				t.Errorf("got ticks %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestGroupTimes(t *testing.T) {
+	colT := NewColumn[time.Time]("t")
+	colY := NewColumn[float64]("y")
+	d := Empty()
+	d.AddColumn(colT)
+	d.AddColumn(colY)
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := time.Now()
+	for i, tm := range []time.Time{
+		base,
+		// The same instant, in another location.
+		base.In(time.FixedZone("UTC+2", 2*60*60)),
+		// The same instant, with a monotonic clock reading.
+		now.Add(base.Sub(now)),
+		base.Add(time.Hour),
+	} {
+		for row := range d.Grow(1) {
+			colT.Set(d, row, tm)
+			colY.Set(d, row, float64(i))
+		}
+	}
+	var groups [][]float64
+	for _, ys := range group(d, []int{0, 1, 2, 3}, colT, colY) {
+		groups = append(groups, ys)
+	}
+	if len(groups) != 2 || len(groups[0]) != 3 || len(groups[1]) != 1 {
+		t.Errorf("got groups %v, want the first three rows grouped together", groups)
+	}
+}
+
+func TestSortSeriesTimes(t *testing.T) {
+	colT := NewColumn[time.Time]("t")
+	colY := NewColumn[float64]("y")
+	d := Empty()
+	d.AddColumn(colT)
+	d.AddColumn(colY)
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	zone := time.FixedZone("UTC+2", 2*60*60)
+	// Rows at the same instant, in different locations, are ordered by Y.
+	for i, tm := range []time.Time{
+		base.Add(time.Hour),
+		base.In(zone),
+		base,
+		base.Add(time.Hour).In(zone),
+	} {
+		for row := range d.Grow(1) {
+			colT.Set(d, row, tm)
+			colY.Set(d, row, float64(3-i))
+		}
+	}
+	s := &series[time.Time, float64]{d: d, rows: []int{0, 1, 2, 3}, x: colT, y: colY}
+	sort.Sort(s)
+	if want := []int{2, 1, 3, 0}; !slices.Equal(s.rows, want) {
+		t.Errorf("got rows in order %v, want %v", s.rows, want)
+	}
+}
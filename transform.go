@@ -0,0 +1,262 @@
+package ggg
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// axisTransform maps positions along an axis into a space in which they're
+// drawn evenly spaced, like their logarithms for a log scale.
+type axisTransform struct {
+	f func(float64) float64
+
+	// ticks places roughly count ticks suited to the transform, or a
+	// default number if count is zero. minor is set if the axis also has
+	// minor ticks.
+	ticks func(lo, hi float64, count int, minor bool) []float64
+
+	// minor, if not nil, places minor ticks suited to the transform.
+	// Otherwise, minor ticks evenly divide the space between ticks.
+	minor TickLocator
+
+	// check, if not nil, returns an error if the transform can't map the
+	// range [lo, hi] of values along the axis named name.
+	check func(name string, lo, hi float64) error
+}
+
+// TickLocator returns the positions of ticks along an axis whose range is
+// [lo, hi].
+type TickLocator func(lo, hi float64) []float64
+
+// Locator places the axis's ticks with l, rather than with the locator
+// suited to the axis's scale and kind of values.
+func Locator(l TickLocator) AxisOption {
+	return func(opts *axis) {
+		opts.locator = l
+	}
+}
+
+// LogScale draws the axis with a logarithmic scale in the provided base,
+// which must be at least 2. The range of the axis must be positive.
+func LogScale(base int) AxisOption {
+	log := logFunc(base)
+	return func(opts *axis) {
+		opts.transform = &axisTransform{
+			f: log,
+			ticks: func(lo, hi float64, count int, minor bool) []float64 {
+				if minor && count == 0 {
+					// Label only the powers of base, which minor ticks
+					// divide.
+					count = -1
+				}
+				return logTicks(base, lo, hi, count)
+			},
+			minor: func(lo, hi float64) []float64 {
+				return logMinorTicks(base, 1, lo, hi)
+			},
+			check: func(name string, lo, hi float64) error {
+				if base < 2 {
+					return fmt.Errorf("specified log scale for %s values, but base %d is less than 2", name, base)
+				}
+				if lo <= 0 || hi <= 0 {
+					return fmt.Errorf("specified log scale, but domain of %s values is zero or negative: [%f, %f]", name, lo, hi)
+				}
+				return nil
+			},
+		}
+	}
+}
+
+// SymLogScale draws the axis with a symmetric logarithmic scale in the
+// provided base, which suits values spanning many orders of magnitude on
+// either side of zero. Values are linear near zero, out to around
+// threshold, and logarithmic beyond it. The base must be at least 2, and
+// the threshold must be positive.
+func SymLogScale(base int, threshold float64) AxisOption {
+	log := logFunc(base)
+	return func(opts *axis) {
+		opts.transform = &axisTransform{
+			f: func(x float64) float64 {
+				return math.Copysign(log(1+math.Abs(x)/threshold), x)
+			},
+			ticks: func(lo, hi float64, count int, _ bool) []float64 {
+				return symLogTicks(base, threshold, lo, hi, count)
+			},
+			minor: func(lo, hi float64) []float64 {
+				return logMinorTicks(base, threshold, lo, hi)
+			},
+			check: func(name string, _, _ float64) error {
+				if base < 2 {
+					return fmt.Errorf("specified symmetric log scale for %s values, but base %d is less than 2", name, base)
+				}
+				if !(threshold > 0) {
+					return fmt.Errorf("specified symmetric log scale for %s values, but threshold %v isn't positive", name, threshold)
+				}
+				return nil
+			},
+		}
+	}
+}
+
+// SqrtScale draws the axis with a square root scale, which spreads out
+// small values. Negative values are mirrored about zero.
+func SqrtScale() AxisOption {
+	return func(opts *axis) {
+		opts.transform = &axisTransform{
+			f: func(x float64) float64 {
+				return math.Copysign(math.Sqrt(math.Abs(x)), x)
+			},
+			ticks: func(lo, hi float64, count int, _ bool) []float64 {
+				return linearTicks(lo, hi, count)
+			},
+		}
+	}
+}
+
+// Reversed reverses the direction of the axis, so that values decrease
+// from left to right, or from bottom to top.
+func Reversed() AxisOption {
+	return func(opts *axis) {
+		opts.reversed = true
+	}
+}
+
+// Break skips the values between lo and hi along the axis, leaving a small
+// gap marked on the axis line, for example to show outliers without
+// squashing the rest of the data. An axis may have several breaks, which
+// shouldn't overlap.
+func Break(lo, hi float64) AxisOption {
+	return func(opts *axis) {
+		opts.breaks = append(opts.breaks, [2]float64{min(lo, hi), max(lo, hi)})
+	}
+}
+
+// breakGap is the size of the gap left by each break, as a fraction of the
+// length of the axis.
+const breakGap = 0.02
+
+// transformed returns a function mapping positions along the axis into the
+// space in which they're drawn evenly spaced, taking into account its
+// transform and breaks.
+func (a *axis) transformed() func(float64) float64 {
+	f := func(x float64) float64 { return x }
+	if a.transform != nil {
+		f = a.transform.f
+	}
+	if len(a.breaks) == 0 {
+		return f
+	}
+
+	// Find the breaks within the range, in the transformed space.
+	var breaks [][2]float64
+	lo, hi := min(f(a.min), f(a.max)), max(f(a.min), f(a.max))
+	skipped := 0.0
+	for _, b := range a.breaks {
+		b0, b1 := max(f(b[0]), lo), min(f(b[1]), hi)
+		if b0 >= b1 {
+			continue
+		}
+		breaks = append(breaks, [2]float64{b0, b1})
+		skipped += b1 - b0
+	}
+	slices.SortFunc(breaks, func(a, b [2]float64) int {
+		return cmp.Compare(a[0], b[0])
+	})
+	gap := breakGap * (hi - lo - skipped)
+	return func(x float64) float64 {
+		t := f(x)
+		shift := 0.0
+		for _, b := range breaks {
+			switch {
+			case t >= b[1]:
+				shift += b[1] - b[0] - gap
+			case t > b[0]:
+				// Squeeze values within the break into the gap.
+				shift += (t - b[0]) * (1 - gap/(b[1]-b[0]))
+			}
+		}
+		return t - shift
+	}
+}
+
+// breakPositions returns where the breaks in the axis are, as mapped by
+// scale.
+func (a *axis) breakPositions(scale scaleFunc) []float64 {
+	var pos []float64
+	for _, b := range a.breaks {
+		lo, hi := max(b[0], min(a.min, a.max)), min(b[1], max(a.min, a.max))
+		if lo < hi {
+			pos = append(pos, (scale(lo)+scale(hi))/2)
+		}
+	}
+	return pos
+}
+
+// symLogTicks places ticks at zero and at threshold times powers of base
+// on either side of it, thinning them out to roughly count ticks, or 9 if
+// count is zero. If there would be few ticks, it places them linearly
+// instead.
+func symLogTicks(base int, threshold, lo, hi float64, count int) []float64 {
+	if base < 2 || threshold <= 0 {
+		return linearTicks(lo, hi, count)
+	}
+	if count <= 0 {
+		count = 9
+	}
+	var pos []float64
+	for v := threshold; v <= max(math.Abs(lo), math.Abs(hi)); v *= float64(base) {
+		pos = append(pos, v)
+	}
+	// Keep at most count/2 ticks on each side of zero.
+	perSide := max(1, count/2)
+	step := max(1, (len(pos)+perSide-1)/perSide)
+	var ticks []float64
+	for i := len(pos) - 1; i >= 0; i-- {
+		if i%step == 0 && -pos[i] >= lo {
+			ticks = append(ticks, -pos[i])
+		}
+	}
+	if lo <= 0 && hi >= 0 {
+		ticks = append(ticks, 0)
+	}
+	for i, v := range pos {
+		if i%step == 0 && v <= hi {
+			ticks = append(ticks, v)
+		}
+	}
+	if len(ticks) < 3 {
+		return linearTicks(lo, hi, count)
+	}
+	return ticks
+}
+
+// logMinorTicks places minor ticks at multiples of unit times powers of
+// base, on either side of zero, between lo and hi.
+func logMinorTicks(base int, unit, lo, hi float64) []float64 {
+	if base < 2 || unit <= 0 {
+		return nil
+	}
+	var ticks []float64
+	// Start at the power of base below the smallest magnitude in range.
+	small := unit
+	if lo > 0 {
+		small = unit * math.Pow(float64(base), math.Floor(logFunc(base)(lo/unit)))
+	} else if hi < 0 {
+		small = unit * math.Pow(float64(base), math.Floor(logFunc(base)(-hi/unit)))
+	}
+	for v := small; v <= max(math.Abs(lo), math.Abs(hi)); v *= float64(base) {
+		for k := 2; k < base; k++ {
+			m := float64(k) * v
+			if m >= lo && m <= hi {
+				ticks = append(ticks, m)
+			}
+			if -m >= lo && -m <= hi {
+				ticks = append(ticks, -m)
+			}
+		}
+	}
+	slices.Sort(ticks)
+	return ticks
+}
@@ -0,0 +1,224 @@
+package ggg
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTransformed(t *testing.T) {
+	type test struct {
+		name     string
+		opts     []AxisOption
+		min, max float64
+		xs, want []float64
+	}
+	for _, ts := range []test{
+		{
+			name: "Linear",
+			min:  0, max: 10,
+			xs:   []float64{-1, 0, 5},
+			want: []float64{-1, 0, 5},
+		},
+		{
+			name: "Log",
+			opts: []AxisOption{LogScale(10)},
+			min:  1, max: 1000,
+			xs:   []float64{1, 100, 1000},
+			want: []float64{0, 2, 3},
+		},
+		{
+			name: "Log2",
+			opts: []AxisOption{LogScale(2)},
+			min:  1, max: 8,
+			xs:   []float64{0.5, 4},
+			want: []float64{-1, 2},
+		},
+		{
+			name: "SymLog",
+			opts: []AxisOption{SymLogScale(10, 1)},
+			min:  -100, max: 100,
+			xs:   []float64{-99, -9, 0, 9, 99},
+			want: []float64{-2, -1, 0, 1, 2},
+		},
+		{
+			name: "SymLogThreshold",
+			opts: []AxisOption{SymLogScale(10, 0.5)},
+			min:  -100, max: 100,
+			xs:   []float64{-4.5, 49.5},
+			want: []float64{-1, 2},
+		},
+		{
+			name: "Sqrt",
+			opts: []AxisOption{SqrtScale()},
+			min:  -4, max: 16,
+			xs:   []float64{-4, 0, 9},
+			want: []float64{-2, 0, 3},
+		},
+		{
+			// The break's 20 units shrink to a gap of 2% of the remaining
+			// 80, and values within it are squeezed into the gap.
+			name: "Break",
+			opts: []AxisOption{Break(40, 60)},
+			min:  0, max: 100,
+			xs:   []float64{30, 40, 50, 60, 100},
+			want: []float64{30, 40, 40.8, 41.6, 81.6},
+		},
+		{
+			name: "BreakReversedBounds",
+			opts: []AxisOption{Break(60, 40)},
+			min:  0, max: 100,
+			xs:   []float64{50, 100},
+			want: []float64{40.8, 81.6},
+		},
+		{
+			// Breaks are clipped to the range of the axis.
+			name: "BreakPartlyOutside",
+			opts: []AxisOption{Break(80, 200)},
+			min:  0, max: 100,
+			xs:   []float64{80, 100},
+			want: []float64{80, 81.6},
+		},
+		{
+			name: "BreakOutside",
+			opts: []AxisOption{Break(200, 300)},
+			min:  0, max: 100,
+			xs:   []float64{50, 100},
+			want: []float64{50, 100},
+		},
+		{
+			name: "Breaks",
+			opts: []AxisOption{Break(60, 80), Break(10, 30)},
+			min:  0, max: 100,
+			xs:   []float64{10, 30, 60, 80, 100},
+			want: []float64{10, 11.2, 41.2, 42.4, 62.4},
+		},
+		{
+			// Breaks are placed in the transformed space.
+			name: "LogBreak",
+			opts: []AxisOption{LogScale(10), Break(10, 100)},
+			min:  1, max: 10000,
+			xs:   []float64{10, 100, 1000},
+			want: []float64{1, 1.06, 2.06},
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			a := axis{min: ts.min, max: ts.max}
+			for _, opt := range ts.opts {
+				opt(&a)
+			}
+			f := a.transformed()
+			var got []float64
+			for _, x := range ts.xs {
+				got = append(got, f(x))
+			}
+			if !approxEqual(got, ts.want) {
+				t.Errorf("got %v, want %v", got, ts.want)
+			}
+		})
+	}
+}
+
+func TestBreakPositions(t *testing.T) {
+	a := axis{min: 0, max: 100}
+	for _, opt := range []AxisOption{Break(40, 60), Break(90, 120), Break(150, 200)} {
+		opt(&a)
+	}
+	got := a.breakPositions(func(x float64) float64 { return 2 * x })
+	if want := []float64{100, 190}; !slices.Equal(got, want) {
+		t.Errorf("got break positions %v, want %v", got, want)
+	}
+}
+
+func TestTransformCheck(t *testing.T) {
+	type test struct {
+		name    string
+		opt     AxisOption
+		lo, hi  float64
+		wantErr bool
+	}
+	for _, ts := range []test{
+		{"Log", LogScale(10), 1, 100, false},
+		{"LogZero", LogScale(10), 0, 100, true},
+		{"LogNegative", LogScale(10), -1, 100, true},
+		{"LogBase1", LogScale(1), 1, 100, true},
+		{"LogBase0", LogScale(0), 1, 100, true},
+		{"SymLog", SymLogScale(10, 1), -100, 100, false},
+		{"SymLogZeroThreshold", SymLogScale(10, 0), -100, 100, true},
+		{"SymLogNegativeThreshold", SymLogScale(10, -1), -100, 100, true},
+		{"SymLogBase1", SymLogScale(1, 1), -100, 100, true},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			var a axis
+			ts.opt(&a)
+			err := a.transform.check("Y", ts.lo, ts.hi)
+			if gotErr := err != nil; gotErr != ts.wantErr {
+				t.Errorf("got error %v, want error: %t", err, ts.wantErr)
+			}
+		})
+	}
+}
+
+func TestSymLogTicks(t *testing.T) {
+	type test struct {
+		name      string
+		base      int
+		threshold float64
+		lo, hi    float64
+		count     int
+		want      []float64
+	}
+	for _, ts := range []test{
+		{
+			name: "Symmetric",
+			base: 10, threshold: 1,
+			lo: -1000, hi: 1000,
+			want: []float64{-1000, -100, -10, -1, 0, 1, 10, 100, 1000},
+		},
+		{
+			name: "Positive",
+			base: 10, threshold: 1,
+			lo: 0, hi: 1000,
+			want: []float64{0, 1, 10, 100, 1000},
+		},
+		{
+			// Every other power is left out to keep to 2 ticks per side.
+			name: "Thinned",
+			base: 10, threshold: 1,
+			lo: -1000, hi: 1000,
+			count: 4,
+			want:  []float64{-100, -1, 0, 1, 100},
+		},
+		{
+			name: "Threshold",
+			base: 2, threshold: 0.5,
+			lo: -2, hi: 2,
+			want: []float64{-2, -1, -0.5, 0, 0.5, 1, 2},
+		},
+		{
+			// Too few powers fit, so ticks are linear, with the same count.
+			name: "Narrow",
+			base: 10, threshold: 1,
+			lo: 2, hi: 8,
+			want: linearTicks(2, 8, 9),
+		},
+		{
+			name: "BadBase",
+			base: 1, threshold: 1,
+			lo: -10, hi: 10,
+			want: linearTicks(-10, 10, 0),
+		},
+		{
+			name: "BadThreshold",
+			base: 10, threshold: 0,
+			lo: -10, hi: 10,
+			want: linearTicks(-10, 10, 0),
+		},
+	} {
+		t.Run(ts.name, func(t *testing.T) {
+			got := symLogTicks(ts.base, ts.threshold, ts.lo, ts.hi, ts.count)
+			if !slices.Equal(got, ts.want) {
+				t.Errorf("got %v, want %v", got, ts.want)
+			}
+		})
+	}
+}